@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"sort"
+	"strconv"
+
+	synthetics "github.com/dollarshaveclub/new-relic-synthetics-go"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/pkg/errors"
+)
+
+// reconcileAlertPolicies attaches a monitor to the alert policies in
+// alert_policy_ids, and detaches it from those no longer listed, by
+// creating and deleting an alert condition per policy. New Relic
+// can't list a policy's conditions through the synthetics client, so
+// the conditions created are tracked in alert_condition_ids, keyed by
+// policy ID.
+func reconcileAlertPolicies(resourceData *schema.ResourceData, client *synthetics.Client) error {
+	conditions := make(map[string]interface{})
+	for policyID, conditionID := range resourceData.Get("alert_condition_ids").(map[string]interface{}) {
+		conditions[policyID] = conditionID
+	}
+
+	// Policies are attached in numerical order, so that which are
+	// attached when one fails is predictable.
+	var ids []int
+	for _, policyID := range resourceData.Get("alert_policy_ids").(*schema.Set).List() {
+		ids = append(ids, policyID.(int))
+	}
+	sort.Ints(ids)
+	policies := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		policies[strconv.Itoa(id)] = true
+	}
+
+	// State is saved after every change so that conditions aren't
+	// lost track of when a later one fails.
+	var err error
+	for policyID, conditionID := range conditions {
+		if policies[policyID] {
+			continue
+		}
+		if err = deleteAlertCondition(client, policyID, conditionID.(string)); err != nil {
+			break
+		}
+		delete(conditions, policyID)
+	}
+	if err == nil {
+		for _, id := range ids {
+			policyID := strconv.Itoa(id)
+			if _, ok := conditions[policyID]; ok {
+				continue
+			}
+			var conditionID string
+			if conditionID, err = createAlertCondition(resourceData, client, policyID); err != nil {
+				break
+			}
+			conditions[policyID] = conditionID
+		}
+	}
+
+	if setErr := setAlertConditions(resourceData, conditions); setErr != nil {
+		return setErr
+	}
+	return err
+}
+
+// setAlertConditions sets alert_condition_ids, and alert_policy_ids
+// to the policies it has conditions in. Terraform saves state even
+// when applying a change fails, so this keeps a policy that couldn't
+// be attached out of state, and it's attached again on the next
+// apply.
+func setAlertConditions(resourceData *schema.ResourceData, conditions map[string]interface{}) error {
+	var policies []interface{}
+	for policyID := range conditions {
+		id, err := strconv.Atoi(policyID)
+		if err != nil {
+			return errors.Wrapf(err, "error: invalid alert policy ID %q", policyID)
+		}
+		policies = append(policies, id)
+	}
+
+	if err := resourceData.Set("alert_condition_ids", conditions); err != nil {
+		return err
+	}
+	return resourceData.Set("alert_policy_ids", policies)
+}
+
+// detachAlertPolicies deletes the alert conditions attaching a
+// monitor to its alert policies.
+func detachAlertPolicies(resourceData *schema.ResourceData, client *synthetics.Client) error {
+	for policyID, conditionID := range resourceData.Get("alert_condition_ids").(map[string]interface{}) {
+		if err := deleteAlertCondition(client, policyID, conditionID.(string)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createAlertCondition creates an alert condition for a monitor in
+// an alert policy and returns its ID.
+func createAlertCondition(resourceData *schema.ResourceData, client *synthetics.Client, policyID string) (string, error) {
+	id, err := strconv.ParseUint(policyID, 10, 0)
+	if err != nil {
+		return "", errors.Wrapf(err, "error: invalid alert policy ID %q", policyID)
+	}
+
+	condition, err := client.CreateAlertCondition(uint(id), &synthetics.CreateAlertConditionArgs{
+		Name:      resourceData.Get("name").(string),
+		MonitorID: resourceData.Id(),
+		Enabled:   true,
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "error: could not attach monitor to alert policy %s", policyID)
+	}
+	return strconv.FormatUint(uint64(condition.ID), 10), nil
+}
+
+// deleteAlertCondition deletes an alert condition in an alert policy.
+// A condition that no longer exists, such as one deleted outside of
+// Terraform, is treated as already deleted.
+func deleteAlertCondition(client *synthetics.Client, policyID, conditionID string) error {
+	policy, err := strconv.ParseUint(policyID, 10, 0)
+	if err != nil {
+		return errors.Wrapf(err, "error: invalid alert policy ID %q", policyID)
+	}
+	id, err := strconv.ParseUint(conditionID, 10, 0)
+	if err != nil {
+		return errors.Wrapf(err, "error: invalid alert condition ID %q", conditionID)
+	}
+
+	if err := client.DeleteAlertCondition(uint(id)); err != nil {
+		if _, getErr := client.GetAlertCondition(uint(policy), uint(id)); isNotFound(getErr) {
+			return nil
+		}
+		return errors.Wrapf(err, "error: could not delete alert condition %s", conditionID)
+	}
+	return nil
+}
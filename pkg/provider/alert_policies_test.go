@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestNRSMonitorAlertPolicies(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.handleCreateMonitor(testMonitorID, `{
+		"id": "`+testMonitorID+`",
+		"name": "monitor",
+		"type": "SIMPLE",
+		"frequency": 10,
+		"uri": "https://example.com",
+		"locations": ["AWS_US_WEST_1"],
+		"status": "ENABLED"
+	}`)
+	fake.Handle("POST", "/v2/alerts_synthetics_conditions/policies/1.json", jsonResponse(http.StatusCreated, `{
+		"synthetics_condition": {"id": 11, "name": "monitor", "monitor_id": "`+testMonitorID+`", "enabled": true}
+	}`))
+	fake.Handle("POST", "/v2/alerts_synthetics_conditions/policies/2.json", jsonResponse(http.StatusCreated, `{
+		"synthetics_condition": {"id": 22, "name": "monitor", "monitor_id": "`+testMonitorID+`", "enabled": true}
+	}`))
+	fake.Handle("DELETE", "/v2/alerts_synthetics_conditions/11.json", jsonResponse(http.StatusOK, ""))
+	fake.Handle("DELETE", "/v2/alerts_synthetics_conditions/22.json", jsonResponse(http.StatusOK, ""))
+	fake.Handle("DELETE", monitorPath(testMonitorID), jsonResponse(http.StatusNoContent, ""))
+
+	raw := map[string]interface{}{
+		"name":             "monitor",
+		"type":             "SIMPLE",
+		"frequency":        10,
+		"uri":              "https://example.com",
+		"locations":        []interface{}{"AWS_US_WEST_1"},
+		"status":           "ENABLED",
+		"alert_policy_ids": []interface{}{1},
+	}
+	state, err := NRSMonitorResource().Apply(nil, planResource(t, NRSMonitorResource(), nil, raw), fake.Meta(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := len(fake.Requests("POST", "/v2/alerts_synthetics_conditions/policies/1.json")); got != 1 {
+		t.Fatalf("expected the monitor to be attached to policy 1, got %d requests", got)
+	}
+	if got := state.Attributes["alert_condition_ids.1"]; got != "11" {
+		t.Fatalf("expected condition 11 for policy 1, got %q", got)
+	}
+
+	raw["alert_policy_ids"] = []interface{}{1, 2}
+	state, err = NRSMonitorResource().Apply(state, planResource(t, NRSMonitorResource(), state, raw), fake.Meta(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := len(fake.Requests("POST", "/v2/alerts_synthetics_conditions/policies/1.json")); got != 1 {
+		t.Fatalf("expected policy 1 to be left alone, got %d requests", got)
+	}
+	if got := len(fake.Requests("POST", "/v2/alerts_synthetics_conditions/policies/2.json")); got != 1 {
+		t.Fatalf("expected the monitor to be attached to policy 2, got %d requests", got)
+	}
+	if got := len(fake.Requests("DELETE", "/v2/alerts_synthetics_conditions/11.json")); got != 0 {
+		t.Fatalf("expected no conditions to be deleted, got %d deletes", got)
+	}
+
+	raw["alert_policy_ids"] = []interface{}{2}
+	state, err = NRSMonitorResource().Apply(state, planResource(t, NRSMonitorResource(), state, raw), fake.Meta(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := len(fake.Requests("DELETE", "/v2/alerts_synthetics_conditions/11.json")); got != 1 {
+		t.Fatalf("expected the monitor to be detached from policy 1, got %d deletes", got)
+	}
+	if got := len(fake.Requests("POST", "/v2/alerts_synthetics_conditions/policies/2.json")); got != 1 {
+		t.Fatalf("expected policy 2 to be left alone, got %d requests", got)
+	}
+	if _, ok := state.Attributes["alert_condition_ids.1"]; ok {
+		t.Fatal("expected policy 1's condition to be removed from state")
+	}
+	if got := state.Attributes["alert_condition_ids.2"]; got != "22" {
+		t.Fatalf("expected condition 22 for policy 2, got %q", got)
+	}
+	if diff := planResource(t, NRSMonitorResource(), state, raw); !diff.Empty() {
+		t.Fatalf("expected an empty plan, got: %#v", diff.Attributes)
+	}
+
+	destroy := &terraform.InstanceDiff{Destroy: true}
+	if _, err := NRSMonitorResource().Apply(state, destroy, fake.Meta(t)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := len(fake.Requests("DELETE", "/v2/alerts_synthetics_conditions/22.json")); got != 1 {
+		t.Fatalf("expected the monitor to be detached from policy 2 on destroy, got %d deletes", got)
+	}
+}
+
+func TestNRSMonitorAlertPolicyAttachFailure(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.handleCreateMonitor(testMonitorID, `{
+		"id": "`+testMonitorID+`",
+		"name": "monitor",
+		"type": "SIMPLE",
+		"frequency": 10,
+		"uri": "https://example.com",
+		"locations": ["AWS_US_WEST_1"],
+		"status": "ENABLED"
+	}`)
+	fake.Handle("POST", "/v2/alerts_synthetics_conditions/policies/1.json", jsonResponse(http.StatusCreated, `{
+		"synthetics_condition": {"id": 11, "name": "monitor", "monitor_id": "`+testMonitorID+`", "enabled": true}
+	}`))
+	fake.Handle("POST", "/v2/alerts_synthetics_conditions/policies/2.json", jsonResponse(http.StatusUnprocessableEntity, `{"error": "policy 2 is locked"}`))
+
+	raw := map[string]interface{}{
+		"name":             "monitor",
+		"type":             "SIMPLE",
+		"frequency":        10,
+		"uri":              "https://example.com",
+		"locations":        []interface{}{"AWS_US_WEST_1"},
+		"status":           "ENABLED",
+		"alert_policy_ids": []interface{}{1, 2},
+	}
+	state, err := NRSMonitorResource().Apply(nil, planResource(t, NRSMonitorResource(), nil, raw), fake.Meta(t))
+	if err == nil {
+		t.Fatal("expected attaching the monitor to policy 2 to fail")
+	}
+	if got := state.Attributes["alert_policy_ids.#"]; got != "1" {
+		t.Fatalf("expected only policy 1 in state, got %s policies: %#v", got, state.Attributes)
+	}
+	if got := state.Attributes["alert_condition_ids.1"]; got != "11" {
+		t.Fatalf("expected condition 11 for policy 1, got %q", got)
+	}
+
+	// The next apply attaches the monitor to policy 2 instead of
+	// planning nothing.
+	fake.Handle("POST", "/v2/alerts_synthetics_conditions/policies/2.json", jsonResponse(http.StatusCreated, `{
+		"synthetics_condition": {"id": 22, "name": "monitor", "monitor_id": "`+testMonitorID+`", "enabled": true}
+	}`))
+	state, err = NRSMonitorResource().Refresh(state, fake.Meta(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	state, err = NRSMonitorResource().Apply(state, planResource(t, NRSMonitorResource(), state, raw), fake.Meta(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := len(fake.Requests("POST", "/v2/alerts_synthetics_conditions/policies/1.json")); got != 1 {
+		t.Fatalf("expected policy 1 to be left alone, got %d requests", got)
+	}
+	if got := state.Attributes["alert_condition_ids.2"]; got != "22" {
+		t.Fatalf("expected condition 22 for policy 2, got %q", got)
+	}
+	if diff := planResource(t, NRSMonitorResource(), state, raw); !diff.Empty() {
+		t.Fatalf("expected an empty plan, got: %#v", diff.Attributes)
+	}
+}
+
+func TestNRSMonitorDestroyWithDeletedAlertCondition(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.Handle("DELETE", "/v2/alerts_synthetics_conditions/11.json", jsonResponse(http.StatusNotFound, `{"error": {"title": "Not found"}}`))
+	fake.Handle("GET", "/v2/alerts_synthetics_conditions.json", jsonResponse(http.StatusOK, `{"synthetics_conditions": []}`))
+	fake.Handle("DELETE", "/v2/alerts_synthetics_conditions/22.json", jsonResponse(http.StatusInternalServerError, ""))
+	fake.Handle("DELETE", monitorPath(testMonitorID), jsonResponse(http.StatusNoContent, ""))
+
+	state := &terraform.InstanceState{
+		ID: testMonitorID,
+		Attributes: map[string]string{
+			"alert_condition_ids.%": "1",
+			"alert_condition_ids.1": "11",
+		},
+	}
+	destroy := &terraform.InstanceDiff{Destroy: true}
+	if _, err := NRSMonitorResource().Apply(state, destroy, fake.Meta(t)); err != nil {
+		t.Fatalf("expected a condition that's already gone to be skipped, got: %s", err)
+	}
+	if got := len(fake.Requests("DELETE", monitorPath(testMonitorID))); got != 1 {
+		t.Fatalf("expected the monitor to be deleted, got %d deletes", got)
+	}
+
+	// A condition that still exists but can't be deleted fails the
+	// destroy.
+	fake.Handle("GET", "/v2/alerts_synthetics_conditions.json", jsonResponse(http.StatusOK, `{
+		"synthetics_conditions": [{"id": 22, "name": "monitor", "monitor_id": "`+testMonitorID+`", "enabled": true}]
+	}`))
+	state.Attributes = map[string]string{
+		"alert_condition_ids.%": "1",
+		"alert_condition_ids.2": "22",
+	}
+	if _, err := NRSMonitorResource().Apply(state, destroy, fake.Meta(t)); err == nil {
+		t.Fatal("expected a condition that can't be deleted to fail the destroy")
+	}
+	if got := len(fake.Requests("DELETE", monitorPath(testMonitorID))); got != 1 {
+		t.Fatalf("expected the monitor not to be deleted, got %d deletes", got)
+	}
+}
@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/pkg/errors"
+)
+
+// NRSMonitorsDataSource returns a Terraform schema for a page of New
+// Relic Synthetics monitors.
+func NRSMonitorsDataSource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"offset": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "The number of monitors to skip",
+				ValidateFunc: validation.IntBetween(0, math.MaxInt32),
+			},
+			"limit": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      maxMonitorPageSize,
+				Description:  "The maximum number of monitors to return, up to 100",
+				ValidateFunc: validation.IntBetween(1, maxMonitorPageSize),
+			},
+			"count": &schema.Schema{
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The total number of monitors in the account",
+			},
+			"monitors": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The monitors in the page",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"type": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"frequency": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"uri": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"locations": &schema.Schema{
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"status": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+		Read: NRSMonitorsRead,
+	}
+}
+
+// NRSMonitorsRead reads a page of Synthetics monitors.
+func NRSMonitorsRead(resourceData *schema.ResourceData, meta interface{}) error {
+	client := meta.(*providerMeta).client
+
+	offset := resourceData.Get("offset").(int)
+	limit := resourceData.Get("limit").(int)
+
+	response, err := client.GetAllMonitors(uint(offset), uint(limit))
+	if err != nil {
+		return errors.Wrap(err, "error: could not list monitors")
+	}
+
+	monitors := make([]map[string]interface{}, 0, len(response.Monitors))
+	for _, monitor := range response.Monitors {
+		monitors = append(monitors, map[string]interface{}{
+			"id":        monitor.ID,
+			"name":      monitor.Name,
+			"type":      monitor.Type,
+			"frequency": int(monitor.Frequency),
+			"uri":       monitor.URI,
+			"locations": monitor.Locations,
+			"status":    monitor.Status,
+		})
+	}
+
+	resourceData.SetId(fmt.Sprintf("%d-%d", offset, limit))
+	if err := resourceData.Set("count", int(response.Count)); err != nil {
+		return err
+	}
+	return resourceData.Set("monitors", monitors)
+}
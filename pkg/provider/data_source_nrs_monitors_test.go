@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestNRSMonitorsRead(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.Handle("GET", "/synthetics/api/v3/monitors", func(w http.ResponseWriter, r *http.Request) {
+		if offset, limit := r.URL.Query().Get("offset"), r.URL.Query().Get("limit"); offset != "2" || limit != "2" {
+			t.Fatalf("expected offset 2 and limit 2, got %q and %q", offset, limit)
+		}
+		jsonResponse(http.StatusOK, `{"count": 5, "monitors": [
+			{"id": "monitor-3", "name": "three", "type": "SIMPLE", "frequency": 10, "locations": ["AWS_US_WEST_1"], "status": "ENABLED", "createdAt": "2017-06-05T15:52:57.830+0000", "modifiedAt": "2017-06-05T15:52:57.830+0000"},
+			{"id": "monitor-4", "name": "four", "type": "SIMPLE", "frequency": 5, "locations": ["AWS_US_EAST_1"], "status": "MUTED", "createdAt": "2017-06-05T15:52:57.830+0000", "modifiedAt": "2017-06-05T15:52:57.830+0000"}
+		]}`)(w, r)
+	})
+
+	resourceData := schema.TestResourceDataRaw(t, NRSMonitorsDataSource().Schema, map[string]interface{}{
+		"offset": 2,
+		"limit":  2,
+	})
+	if err := NRSMonitorsRead(resourceData, fake.Meta(t)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state := resourceData.State()
+	expected := map[string]string{
+		"count":                  "5",
+		"monitors.#":             "2",
+		"monitors.0.id":          "monitor-3",
+		"monitors.0.locations.0": "AWS_US_WEST_1",
+		"monitors.1.id":          "monitor-4",
+		"monitors.1.frequency":   "5",
+		"monitors.1.status":      "MUTED",
+	}
+	for key, value := range expected {
+		if got := state.Attributes[key]; got != value {
+			t.Fatalf("expected %s to be %q, got %q", key, value, got)
+		}
+	}
+}
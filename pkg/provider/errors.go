@@ -0,0 +1,19 @@
+package provider
+
+import (
+	synthetics "github.com/dollarshaveclub/new-relic-synthetics-go"
+	"github.com/pkg/errors"
+)
+
+// isNotFound reports whether an error returned by the synthetics
+// client, possibly wrapped, indicates that the requested object
+// doesn't exist.
+func isNotFound(err error) bool {
+	switch errors.Cause(err) {
+	case synthetics.ErrMonitorNotFound,
+		synthetics.ErrMonitorScriptNotFound,
+		synthetics.ErrAlertConditionNotFound:
+		return true
+	}
+	return false
+}
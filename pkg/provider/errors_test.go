@@ -0,0 +1,28 @@
+package provider
+
+import (
+	"testing"
+
+	synthetics "github.com/dollarshaveclub/new-relic-synthetics-go"
+	"github.com/pkg/errors"
+)
+
+func TestIsNotFound(t *testing.T) {
+	cases := []struct {
+		err      error
+		notFound bool
+	}{
+		{synthetics.ErrMonitorNotFound, true},
+		{synthetics.ErrMonitorScriptNotFound, true},
+		{synthetics.ErrAlertConditionNotFound, true},
+		{errors.Wrap(synthetics.ErrMonitorNotFound, "error: could not get monitor"), true},
+		{errors.New("error: invalid response from GetMonitor with code 500"), false},
+		{nil, false},
+	}
+
+	for _, c := range cases {
+		if got := isNotFound(c.err); got != c.notFound {
+			t.Errorf("isNotFound(%v): expected %t, got %t", c.err, c.notFound, got)
+		}
+	}
+}
@@ -0,0 +1,543 @@
+package provider
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// requestIDHeader is the response header New Relic uses to identify
+// a request, which is useful when contacting support.
+const requestIDHeader = "X-Request-Id"
+
+// createdGracePeriod is how long after a resource is created that a
+// 404 for it is treated as New Relic not having caught up yet, rather
+// than the resource not existing.
+const createdGracePeriod = time.Minute
+
+// redirectError is returned in place of following a redirect. New
+// Relic doesn't redirect API requests, and following one elsewhere
+// would send the API key along with it.
+type redirectError struct {
+	location *url.URL
+}
+
+func (r *redirectError) Error() string {
+	return fmt.Sprintf("error: New Relic redirected the request to %s, possibly through a misconfigured proxy. Redirects aren't followed", r.location)
+}
+
+// defaultMinTLSVersion is the lowest TLS version used when none is
+// configured.
+const defaultMinTLSVersion = tls.VersionTLS12
+
+// tlsVersions maps the names of TLS versions accepted by the
+// min_tls_version provider argument to their versions.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// writeOperation is a monitor write whose success status can be
+// configured.
+type writeOperation struct {
+	// path matches the request paths of the write.
+	path *regexp.Regexp
+
+	// status is the only success status the synthetics client
+	// accepts for the write.
+	status int
+
+	// accepted reports whether a response is also a success without
+	// any accepted statuses configured. It's nil for writes that
+	// only succeed with status.
+	accepted func(response *http.Response, body []byte) bool
+}
+
+// writeOperations are the monitor writes whose accepted success
+// statuses can be configured, keyed by method: creating a monitor,
+// updating one, and updating a monitor's script.
+var writeOperations = map[string]writeOperation{
+	"POST": {
+		path:   regexp.MustCompile(`^/synthetics/api/v3/monitors$`),
+		status: http.StatusCreated,
+		// The synthetics client reads the new monitor from the
+		// Location header, so a 202 without one can't be used.
+		accepted: func(response *http.Response, body []byte) bool {
+			return response.StatusCode == http.StatusAccepted && response.Header.Get("Location") != ""
+		},
+	},
+	"PATCH": {
+		path:   regexp.MustCompile(`^/synthetics/api/v3/monitors/[^/]+$`),
+		status: http.StatusNoContent,
+		// A 200 with a body may carry something the synthetics
+		// client would ignore, so only an empty one is a 204.
+		accepted: func(response *http.Response, body []byte) bool {
+			return response.StatusCode == http.StatusAccepted ||
+				response.StatusCode == http.StatusOK && len(body) == 0
+		},
+	},
+	"PUT": {
+		path:   regexp.MustCompile(`^/synthetics/api/v3/monitors/[^/]+/script$`),
+		status: http.StatusNoContent,
+	},
+}
+
+// parseAcceptedStatuses parses the accepted_statuses provider
+// argument, which maps methods in writeOperations to comma-separated
+// 2xx statuses.
+func parseAcceptedStatuses(raw map[string]interface{}) (map[string][]int, error) {
+	statuses := make(map[string][]int, len(raw))
+	for method, codes := range raw {
+		if _, ok := writeOperations[method]; !ok {
+			return nil, errors.Errorf("accepted statuses can only be set for POST, PATCH, and PUT, got: %s", method)
+		}
+		for _, code := range strings.Split(fmt.Sprint(codes), ",") {
+			status, err := strconv.Atoi(strings.TrimSpace(code))
+			if err != nil || status < 200 || status > 299 {
+				return nil, errors.Errorf("accepted statuses for %s must be 2xx status codes, got: %q", method, code)
+			}
+			statuses[method] = append(statuses[method], status)
+		}
+		sort.Ints(statuses[method])
+	}
+	return statuses, nil
+}
+
+func validateAcceptedStatuses(i interface{}, k string) ([]string, []error) {
+	if _, err := parseAcceptedStatuses(i.(map[string]interface{})); err != nil {
+		return nil, []error{errors.Wrap(err, k)}
+	}
+	return nil, nil
+}
+
+// idempotentMethods are the HTTP methods that are safe to retry.
+var idempotentMethods = map[string]bool{
+	"GET":    true,
+	"PUT":    true,
+	"DELETE": true,
+}
+
+// httpClient is the HTTP client used by the synthetics client. It
+// retries idempotent requests that fail transiently. Rate limiting
+// is retried by the synthetics client itself.
+type httpClient struct {
+	client *http.Client
+
+	// The number of times to retry a transient failure, and the
+	// wait before the first retry, which doubles on every attempt.
+	retries   uint
+	retryWait time.Duration
+
+	// retryCreate is whether POST requests are retried too.
+	retryCreate bool
+
+	// acceptedStatuses are the statuses, by method, that are treated
+	// as success for the writes in writeOperations, in addition to
+	// the status the synthetics client expects and the responses the
+	// operations accept by default.
+	acceptedStatuses map[string]map[int]bool
+
+	// redactedFields are the lowercase names of JSON fields whose
+	// values are redacted from logged bodies.
+	redactedFields map[string]bool
+
+	// maxLoggedBody is the number of bytes of a body that are
+	// logged. Zero means bodies are logged whole.
+	maxLoggedBody uint
+
+	// slowRequestThreshold is the latency above which a request is
+	// logged as slow. Zero disables the warning.
+	slowRequestThreshold time.Duration
+
+	// slots limits the number of requests in flight. It's nil when
+	// concurrency is unlimited.
+	slots chan struct{}
+
+	mu     sync.Mutex
+	counts map[requestKey]uint64
+
+	// created maps the URLs of recently created resources to when
+	// createdGracePeriod ends for them.
+	created map[string]time.Time
+}
+
+// requestKey identifies a kind of request for metrics. StatusCode is
+// zero for requests that failed without a response.
+type requestKey struct {
+	Method     string
+	StatusCode int
+}
+
+func (k requestKey) String() string {
+	if k.StatusCode == 0 {
+		return k.Method + " failed"
+	}
+	return fmt.Sprintf("%s %d", k.Method, k.StatusCode)
+}
+
+// httpClientConfig configures an httpClient.
+type httpClientConfig struct {
+	// Retries is the number of times to retry a transient failure.
+	Retries uint
+
+	// RetryCreate retries POST requests, which create resources, as
+	// well as idempotent ones. A retried creation whose first attempt
+	// reached New Relic can create a duplicate.
+	RetryCreate bool
+
+	// ConnectTimeout bounds establishing a connection, including
+	// the TLS handshake, while RequestTimeout bounds an entire
+	// request, including reading the response body.
+	ConnectTimeout time.Duration
+	RequestTimeout time.Duration
+
+	// AcceptedStatuses are success statuses, by method, accepted for
+	// the writes in writeOperations in addition to the one the
+	// synthetics client expects. A 202 to a creation with a Location
+	// header, and a 202 or an empty 200 to an update, are accepted
+	// regardless.
+	AcceptedStatuses map[string][]int
+
+	// MinTLSVersion is the lowest TLS version used to connect, such
+	// as tls.VersionTLS12. Zero means defaultMinTLSVersion.
+	MinTLSVersion uint16
+
+	// RedactedFields are JSON fields, in addition to
+	// defaultRedactedFields, whose values are redacted from logged
+	// request and response bodies.
+	RedactedFields []string
+
+	// MaxLoggedBodyLength truncates logged request and response
+	// bodies to this many bytes. Zero means no limit.
+	MaxLoggedBodyLength uint
+
+	// SlowRequestThreshold logs a warning for requests that take
+	// longer than it, including reading the response body. Zero
+	// disables the warning.
+	SlowRequestThreshold time.Duration
+
+	// MaxConcurrentRequests limits the number of requests in flight
+	// at once. Zero means no limit.
+	MaxConcurrentRequests uint
+}
+
+func newHTTPClient(config httpClientConfig) *httpClient {
+	dialer := &net.Dialer{
+		Timeout:   config.ConnectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+	minTLSVersion := config.MinTLSVersion
+	if minTLSVersion == 0 {
+		minTLSVersion = defaultMinTLSVersion
+	}
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         dialer.DialContext,
+		TLSClientConfig:     &tls.Config{MinVersion: minTLSVersion},
+		TLSHandshakeTimeout: config.ConnectTimeout,
+	}
+
+	redactedFields := make(map[string]bool)
+	for _, field := range append(defaultRedactedFields, config.RedactedFields...) {
+		redactedFields[strings.ToLower(field)] = true
+	}
+
+	acceptedStatuses := make(map[string]map[int]bool)
+	for method, statuses := range config.AcceptedStatuses {
+		acceptedStatuses[method] = make(map[int]bool)
+		for _, status := range statuses {
+			acceptedStatuses[method][status] = true
+		}
+	}
+
+	var slots chan struct{}
+	if config.MaxConcurrentRequests > 0 {
+		slots = make(chan struct{}, config.MaxConcurrentRequests)
+	}
+
+	return &httpClient{
+		client: &http.Client{
+			Transport:     transport,
+			Timeout:       config.RequestTimeout,
+			CheckRedirect: checkRedirect,
+		},
+		retries:        config.Retries,
+		retryWait:      time.Second,
+		retryCreate:    config.RetryCreate,
+		redactedFields: redactedFields,
+		maxLoggedBody:  config.MaxLoggedBodyLength,
+		slots:          slots,
+		counts:         make(map[requestKey]uint64),
+		created:        make(map[string]time.Time),
+
+		acceptedStatuses:     acceptedStatuses,
+		slowRequestThreshold: config.SlowRequestThreshold,
+	}
+}
+
+// checkRedirect refuses to follow redirects.
+func checkRedirect(request *http.Request, via []*http.Request) error {
+	return &redirectError{location: request.URL}
+}
+
+// RequestCounts returns the number of requests made, including
+// retries, by method and status code.
+func (h *httpClient) RequestCounts() map[requestKey]uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	counts := make(map[requestKey]uint64, len(h.counts))
+	for key, count := range h.counts {
+		counts[key] = count
+	}
+	return counts
+}
+
+func (h *httpClient) countRequest(method string, statusCode int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.counts[requestKey{Method: method, StatusCode: statusCode}]++
+}
+
+// recordCreated records the URL of a resource created by a response.
+func (h *httpClient) recordCreated(response *http.Response) {
+	location := response.Header.Get("Location")
+	if response.StatusCode != http.StatusCreated || location == "" {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now()
+	for url, end := range h.created {
+		if now.After(end) {
+			delete(h.created, url)
+		}
+	}
+	h.created[location] = now.Add(createdGracePeriod)
+}
+
+// forgetCreated stops treating a URL as recently created, once the
+// resource has been deleted. 404s for it are then expected, such as
+// while waiting for a deletion to take effect, and aren't retried.
+func (h *httpClient) forgetCreated(url string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.created, url)
+}
+
+// recentlyCreated reports whether a URL is of a resource created
+// within createdGracePeriod.
+func (h *httpClient) recentlyCreated(url string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	end, ok := h.created[url]
+	return ok && time.Now().Before(end)
+}
+
+// warnIfSlow logs a warning for a request attempt that took longer
+// than slowRequestThreshold, which can point to New Relic's API being
+// degraded.
+func (h *httpClient) warnIfSlow(request *http.Request, latency time.Duration) {
+	if h.slowRequestThreshold > 0 && latency > h.slowRequestThreshold {
+		log.Printf("[WARN] synthetics %s %s took %s, longer than the slow request threshold of %s", request.Method, request.URL, latency, h.slowRequestThreshold)
+	}
+}
+
+// Do performs a request, retrying it if it's idempotent and fails
+// transiently. Non-idempotent requests, like monitor creation, aren't
+// retried since that could create duplicates, unless retryCreate is
+// set for POST requests.
+func (h *httpClient) Do(request *http.Request) (*http.Response, error) {
+	retries := h.retries
+	if !idempotentMethods[request.Method] && !(h.retryCreate && request.Method == "POST") {
+		retries = 0
+	}
+
+	// New Relic may not return a monitor for a moment after creating
+	// it, so reads of new resources retry 404s too.
+	url := request.URL.String()
+	retryNotFound := request.Method == "GET" && h.recentlyCreated(url)
+
+	for i := uint(0); ; i++ {
+		response, err := h.send(request)
+		if err == nil && request.Method == "DELETE" {
+			h.forgetCreated(url)
+		}
+		notFound := retryNotFound && err == nil && response.StatusCode == http.StatusNotFound
+		if i == retries || !(notFound || isTransient(response, err)) {
+			return response, err
+		}
+		if response != nil {
+			response.Body.Close()
+		}
+
+		if request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			request.Body = body
+		}
+
+		log.Printf("[DEBUG] transient failure from synthetics, retrying %s %s", request.Method, request.URL)
+		time.Sleep((1 << i) * h.retryWait)
+	}
+}
+
+// send performs a single attempt of a request. Each attempt holds a
+// concurrency slot only while it's in flight, so requests waiting to
+// retry don't block others.
+func (h *httpClient) send(request *http.Request) (*http.Response, error) {
+	if h.slots != nil {
+		h.slots <- struct{}{}
+		defer func() { <-h.slots }()
+	}
+
+	if request.GetBody != nil {
+		if body, err := request.GetBody(); err == nil {
+			requestBody, _ := ioutil.ReadAll(body)
+			h.logBody("request", request, requestBody)
+		}
+	}
+
+	// Setting Accept-Encoding stops the transport from decompressing
+	// responses itself, so decompression is handled explicitly below
+	// regardless of what the transport does.
+	if request.Header.Get("Accept-Encoding") == "" {
+		request.Header.Set("Accept-Encoding", "gzip")
+	}
+
+	start := time.Now()
+	response, err := h.client.Do(request)
+	if err != nil {
+		latency := time.Since(start)
+		h.warnIfSlow(request, latency)
+		h.countRequest(request.Method, 0)
+		log.Printf("[DEBUG] synthetics %s %s failed: %s", request.Method, request.URL, err)
+		return nil, err
+	}
+	h.countRequest(request.Method, response.StatusCode)
+
+	responseBody, err := readBody(response)
+	response.Body.Close()
+	latency := time.Since(start)
+	h.warnIfSlow(request, latency)
+	if err != nil {
+		return nil, err
+	}
+	h.normalizeWriteStatus(request, response, responseBody)
+	h.recordCreated(response)
+	response.Body = ioutil.NopCloser(bytes.NewReader(responseBody))
+	h.logBody("response", request, responseBody)
+
+	requestID := response.Header.Get(requestIDHeader)
+	log.Printf("[DEBUG] synthetics %s %s: %d in %s (request ID: %s)", request.Method, request.URL, response.StatusCode, latency, requestID)
+
+	// The synthetics client includes the body of failed responses
+	// in its errors, so this is how the request ID reaches users.
+	if requestID != "" && response.StatusCode >= http.StatusBadRequest {
+		response.Body = &requestIDBody{
+			Reader: io.MultiReader(response.Body, strings.NewReader(" (request ID: "+requestID+")")),
+			Closer: response.Body,
+		}
+	}
+
+	return response, nil
+}
+
+// normalizeWriteStatus rewrites the status of a response to a
+// monitor write to the status the synthetics client expects, if the
+// write's operation accepts the response by default or the status is
+// one of acceptedStatuses for the request's method. The synthetics
+// client only accepts the exact status it's documented to get, so
+// this is how gateways that answer differently are tolerated.
+//
+// A creation accepted with a 202 isn't polled separately. The
+// synthetics client reads the new monitor from the Location header
+// straight away, and that read retries 404s like any read of a newly
+// created monitor, so creation completes once the monitor exists.
+func (h *httpClient) normalizeWriteStatus(request *http.Request, response *http.Response, body []byte) {
+	operation, ok := writeOperations[request.Method]
+	if !ok || !operation.path.MatchString(request.URL.Path) {
+		return
+	}
+	status := response.StatusCode
+	if status == operation.status {
+		return
+	}
+	if !h.acceptedStatuses[request.Method][status] && (operation.accepted == nil || !operation.accepted(response, body)) {
+		return
+	}
+
+	log.Printf("[DEBUG] synthetics %s %s returned %d, treating it as %d", request.Method, request.URL, status, operation.status)
+	response.StatusCode = operation.status
+	response.Status = fmt.Sprintf("%d %s", operation.status, http.StatusText(operation.status))
+}
+
+// readBody reads a response body, decompressing it if it's gzipped.
+// The response's headers are updated to describe the decompressed
+// body.
+func readBody(response *http.Response) ([]byte, error) {
+	if !strings.EqualFold(response.Header.Get("Content-Encoding"), "gzip") {
+		return ioutil.ReadAll(response.Body)
+	}
+
+	reader, err := gzip.NewReader(response.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "error: could not decompress response")
+	}
+	defer reader.Close()
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "error: could not decompress response")
+	}
+
+	response.Header.Del("Content-Encoding")
+	response.Header.Del("Content-Length")
+	response.ContentLength = int64(len(body))
+	response.Uncompressed = true
+	return body, nil
+}
+
+// requestIDBody is a response body with a request ID appended.
+type requestIDBody struct {
+	io.Reader
+	io.Closer
+}
+
+// isTransient reports whether a request failed in a way that may
+// succeed on retry.
+func isTransient(response *http.Response, err error) bool {
+	if urlErr, ok := err.(*url.Error); ok {
+		if _, ok := urlErr.Err.(*redirectError); ok {
+			return false
+		}
+	}
+	if err != nil {
+		return true
+	}
+
+	switch response.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
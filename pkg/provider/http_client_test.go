@@ -0,0 +1,716 @@
+package provider
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/tls"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	synthetics "github.com/dollarshaveclub/new-relic-synthetics-go"
+)
+
+// newTestHTTPClient returns an httpClient that doesn't wait between
+// retries.
+func newTestHTTPClient(config httpClientConfig) *httpClient {
+	client := newHTTPClient(config)
+	client.retryWait = 0
+	return client
+}
+
+// rewriteTransport sends every request to a test server, regardless
+// of the host the synthetics client addresses.
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (r *rewriteTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	request.URL.Scheme = r.target.Scheme
+	request.URL.Host = r.target.Host
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+// newTestSyntheticsClient returns a synthetics client that uses an
+// httpClient to talk to a test server.
+func newTestSyntheticsClient(t *testing.T, server *httptest.Server, client *httpClient) *synthetics.Client {
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	client.client.Transport = &rewriteTransport{target: target}
+
+	syntheticsClient, err := synthetics.NewClient(func(s *synthetics.Client) {
+		s.APIKey = "test-api-key"
+		s.HTTPClient = client
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return syntheticsClient
+}
+
+func TestHTTPClientRetriesIdempotentMethods(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		method string
+		hits   int32
+	}{
+		{"GET", 4},
+		{"PUT", 4},
+		{"DELETE", 4},
+		{"POST", 1},
+	}
+
+	for _, c := range cases {
+		atomic.StoreInt32(&hits, 0)
+
+		request, err := http.NewRequest(c.method, server.URL, strings.NewReader("{}"))
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		response, err := newTestHTTPClient(httpClientConfig{Retries: 3}).Do(request)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		response.Body.Close()
+
+		if response.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("%s: expected status 503, got %d", c.method, response.StatusCode)
+		}
+		if got := atomic.LoadInt32(&hits); got != c.hits {
+			t.Fatalf("%s: expected %d requests, got %d", c.method, c.hits, got)
+		}
+	}
+}
+
+func TestHTTPClientRetryCreate(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	for _, c := range []struct {
+		retryCreate bool
+		hits        int32
+	}{
+		{false, 1},
+		{true, 4},
+	} {
+		atomic.StoreInt32(&hits, 0)
+
+		request, err := http.NewRequest("POST", server.URL, strings.NewReader("{}"))
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		response, err := newTestHTTPClient(httpClientConfig{Retries: 3, RetryCreate: c.retryCreate}).Do(request)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		response.Body.Close()
+
+		if got := atomic.LoadInt32(&hits); got != c.hits {
+			t.Fatalf("retry_create %t: expected %d requests, got %d", c.retryCreate, c.hits, got)
+		}
+	}
+}
+
+func TestHTTPClientRetryResendsBody(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	request, err := http.NewRequest("PUT", server.URL, strings.NewReader(`{"scriptText":""}`))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	response, err := newTestHTTPClient(httpClientConfig{Retries: 3}).Do(request)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", response.StatusCode)
+	}
+	if len(bodies) != 2 || bodies[1] != bodies[0] {
+		t.Fatalf("expected the body to be resent, got %q", bodies)
+	}
+}
+
+func TestHTTPClientConnectTimeout(t *testing.T) {
+	// Accept connections but never complete a TLS handshake.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	client := newTestHTTPClient(httpClientConfig{
+		ConnectTimeout: 100 * time.Millisecond,
+		RequestTimeout: 10 * time.Second,
+	})
+	request, err := http.NewRequest("GET", "https://"+listener.Addr().String(), nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	start := time.Now()
+	_, err = client.Do(request)
+	if err == nil {
+		t.Fatal("expected a connect timeout")
+	}
+	if !strings.Contains(err.Error(), "TLS handshake timeout") {
+		t.Fatalf("expected a TLS handshake timeout, got: %s", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected the connect timeout to fire before the request timeout, took %s", elapsed)
+	}
+}
+
+func TestHTTPClientRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := newTestHTTPClient(httpClientConfig{
+		ConnectTimeout: 10 * time.Second,
+		RequestTimeout: 50 * time.Millisecond,
+	})
+	request, err := http.NewRequest("POST", server.URL, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if _, err := client.Do(request); err == nil {
+		t.Fatal("expected a request timeout")
+	}
+}
+
+func TestHTTPClientRequestIDInErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "request-1234")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": "bad monitor"}`))
+	}))
+	defer server.Close()
+
+	client := newTestSyntheticsClient(t, server, newTestHTTPClient(httpClientConfig{}))
+	_, err := client.GetMonitor("monitor-id")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(err.Error(), "request-1234") {
+		t.Fatalf("expected the request ID in the error, got: %s", err)
+	}
+}
+
+func TestHTTPClientRequestCounts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := newTestHTTPClient(httpClientConfig{})
+	for _, method := range []string{"GET", "GET", "POST", "DELETE"} {
+		request, err := http.NewRequest(method, server.URL, nil)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		response, err := client.Do(request)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		response.Body.Close()
+	}
+
+	expected := map[requestKey]uint64{
+		{Method: "GET", StatusCode: http.StatusOK}:          2,
+		{Method: "POST", StatusCode: http.StatusOK}:         1,
+		{Method: "DELETE", StatusCode: http.StatusNotFound}: 1,
+	}
+	counts := client.RequestCounts()
+	if len(counts) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, counts)
+	}
+	for key, count := range expected {
+		if counts[key] != count {
+			t.Fatalf("expected %v, got %v", expected, counts)
+		}
+	}
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	(&providerMeta{httpClient: client}).logRequestCounts("creating a monitor")
+	want := "[DEBUG] synthetics requests after creating a monitor: DELETE 404: 1, GET 200: 2, POST 200: 1\n"
+	if !strings.HasSuffix(logs.String(), want) {
+		t.Fatalf("expected %q in logs:\n%s", want, logs.String())
+	}
+}
+
+func TestHTTPClientRetriesReadsOfCreatedMonitors(t *testing.T) {
+	var gets int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /synthetics/api/v3/monitors":
+			w.Header().Set("Location", "https://synthetics.newrelic.com/synthetics/api/v3/monitors/"+testMonitorID)
+			w.WriteHeader(http.StatusCreated)
+		case "GET /synthetics/api/v3/monitors/" + testMonitorID:
+			if atomic.AddInt32(&gets, 1) == 1 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`{"id": "` + testMonitorID + `", "name": "monitor"}`))
+		case "GET /synthetics/api/v3/monitors/missing-id":
+			atomic.AddInt32(&gets, 1)
+			w.WriteHeader(http.StatusNotFound)
+		case "DELETE /synthetics/api/v3/monitors/" + testMonitorID:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.Error(w, "unexpected request", http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestSyntheticsClient(t, server, newTestHTTPClient(httpClientConfig{Retries: 3}))
+	monitor, err := client.CreateMonitor(&synthetics.CreateMonitorArgs{Name: "monitor"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if monitor.ID != testMonitorID || atomic.LoadInt32(&gets) != 2 {
+		t.Fatalf("expected the monitor after one retry, got %#v after %d requests", monitor, gets)
+	}
+
+	// Monitors that weren't just created aren't retried.
+	atomic.StoreInt32(&gets, 0)
+	if _, err := client.GetMonitor("missing-id"); err != synthetics.ErrMonitorNotFound {
+		t.Fatalf("expected ErrMonitorNotFound, got: %v", err)
+	}
+	if got := atomic.LoadInt32(&gets); got != 1 {
+		t.Fatalf("expected 1 request, got %d", got)
+	}
+
+	// Once a created monitor is deleted, 404s for it aren't retried.
+	if err := client.DeleteMonitor(testMonitorID); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	atomic.StoreInt32(&gets, 0)
+	if _, err := client.GetMonitor(testMonitorID); err == nil {
+		t.Fatal("expected the deleted monitor not to be found")
+	}
+	if got := atomic.LoadInt32(&gets); got != 1 {
+		t.Fatalf("expected 1 request after deletion, got %d", got)
+	}
+}
+
+func TestHTTPClientGzipResponses(t *testing.T) {
+	var acceptEncoding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding = r.Header.Get("Accept-Encoding")
+		w.Header().Set("Content-Encoding", "gzip")
+		writer := gzip.NewWriter(w)
+		writer.Write([]byte(`{"id": "` + testMonitorID + `", "name": "monitor"}`))
+		writer.Close()
+	}))
+	defer server.Close()
+
+	client := newTestSyntheticsClient(t, server, newTestHTTPClient(httpClientConfig{}))
+	monitor, err := client.GetMonitor(testMonitorID)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if acceptEncoding != "gzip" {
+		t.Fatalf("expected gzip to be accepted, got %q", acceptEncoding)
+	}
+	if monitor.Name != "monitor" {
+		t.Fatalf("expected the gzipped monitor to be decoded, got: %#v", monitor)
+	}
+}
+
+func TestHTTPClientMaxConcurrentRequests(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := newTestHTTPClient(httpClientConfig{MaxConcurrentRequests: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			request, err := http.NewRequest("GET", server.URL, nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			response, err := client.Do(request)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			response.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&maxInFlight); got != 2 {
+		t.Fatalf("expected at most 2 requests in flight, got %d", got)
+	}
+}
+
+func TestHTTPClientRedactsLoggedBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"scriptLocations": [{"name": "private", "hmac": "response-hmac"}], "header": {"Token": "response-token"}}`))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	client := newTestHTTPClient(httpClientConfig{RedactedFields: []string{"token"}})
+	request, err := http.NewRequest("PUT", server.URL, strings.NewReader(`{"scriptLocations": [{"name": "private", "hmac": "request-hmac"}], "token": "request-token"}`))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	body, _ := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+
+	for _, secret := range []string{"request-hmac", "request-token", "response-hmac", "response-token"} {
+		if strings.Contains(logs.String(), secret) {
+			t.Fatalf("expected %s to be redacted from logs:\n%s", secret, logs.String())
+		}
+	}
+	if !strings.Contains(logs.String(), redactedValue) || !strings.Contains(logs.String(), "private") {
+		t.Fatalf("expected redacted bodies to be logged:\n%s", logs.String())
+	}
+	if !strings.Contains(string(body), "response-hmac") {
+		t.Fatalf("expected the response body to be passed through unredacted, got: %s", body)
+	}
+}
+
+func TestHTTPClientDisallowsRedirects(t *testing.T) {
+	var redirected int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&redirected, 1)
+		if r.Header.Get("X-Api-Key") != "" {
+			t.Errorf("expected the API key not to be sent to the redirect target")
+		}
+	}))
+	defer target.Close()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.Redirect(w, r, target.URL+"/monitors", http.StatusMovedPermanently)
+	}))
+	defer server.Close()
+
+	// Unlike rewriteTransport, only requests to New Relic are sent to
+	// the test server, so a followed redirect would reach the target.
+	client := newTestHTTPClient(httpClientConfig{Retries: 3})
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	client.client.Transport = roundTripFunc(func(request *http.Request) (*http.Response, error) {
+		if request.URL.Host == "synthetics.newrelic.com" {
+			request.URL.Scheme = serverURL.Scheme
+			request.URL.Host = serverURL.Host
+		}
+		return http.DefaultTransport.RoundTrip(request)
+	})
+	syntheticsClient, err := synthetics.NewClient(func(s *synthetics.Client) {
+		s.APIKey = "test-api-key"
+		s.HTTPClient = client
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	_, err = syntheticsClient.GetMonitor("monitor-id")
+	if err == nil || !strings.Contains(err.Error(), "redirected the request to "+target.URL+"/monitors") {
+		t.Fatalf("expected a redirect error naming the target, got: %v", err)
+	}
+	if got := atomic.LoadInt32(&redirected); got != 0 {
+		t.Fatalf("expected the redirect not to be followed, got %d requests to the target", got)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Fatalf("expected the redirect not to be retried, got %d requests", got)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+func TestHTTPClientMinTLSVersion(t *testing.T) {
+	for _, test := range []struct {
+		configured uint16
+		expected   uint16
+	}{
+		{0, tls.VersionTLS12},
+		{tls.VersionTLS13, tls.VersionTLS13},
+	} {
+		client := newHTTPClient(httpClientConfig{MinTLSVersion: test.configured})
+		transport := client.client.Transport.(*http.Transport)
+		if got := transport.TLSClientConfig.MinVersion; got != test.expected {
+			t.Fatalf("expected minimum TLS version %x for %x, got %x", test.expected, test.configured, got)
+		}
+	}
+}
+
+func TestHTTPClientAcceptsAcceptedCreations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /synthetics/api/v3/monitors":
+			w.Header().Set("Location", "https://synthetics.newrelic.com/synthetics/api/v3/monitors/"+testMonitorID)
+			w.WriteHeader(http.StatusAccepted)
+		case "GET /synthetics/api/v3/monitors/" + testMonitorID:
+			w.Write([]byte(`{"id": "` + testMonitorID + `", "name": "monitor"}`))
+		case "PATCH /synthetics/api/v3/monitors/" + testMonitorID:
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.Error(w, "unexpected request", http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestSyntheticsClient(t, server, newTestHTTPClient(httpClientConfig{}))
+	monitor, err := client.CreateMonitor(&synthetics.CreateMonitorArgs{Name: "monitor"})
+	if err != nil {
+		t.Fatalf("expected an accepted creation to succeed by default, got: %s", err)
+	}
+	if monitor.ID != testMonitorID {
+		t.Fatalf("expected the monitor to be created, got %#v", monitor)
+	}
+	if _, err := client.UpdateMonitor(monitor.ID, &synthetics.UpdateMonitorArgs{Name: "renamed"}); err != nil {
+		t.Fatalf("expected an accepted update to succeed by default, got: %s", err)
+	}
+
+	// Without a Location header there's no monitor to read.
+	unlocated := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer unlocated.Close()
+
+	client = newTestSyntheticsClient(t, unlocated, newTestHTTPClient(httpClientConfig{}))
+	if _, err := client.CreateMonitor(&synthetics.CreateMonitorArgs{Name: "monitor"}); err == nil || !strings.Contains(err.Error(), "code 202") {
+		t.Fatalf("expected an accepted creation without a Location header to fail, got: %v", err)
+	}
+}
+
+func TestHTTPClientAcceptedStatuses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /synthetics/api/v3/monitors":
+			w.Header().Set("Location", "https://synthetics.newrelic.com/synthetics/api/v3/monitors/"+testMonitorID)
+			w.Write([]byte(`{}`))
+		case "GET /synthetics/api/v3/monitors/" + testMonitorID:
+			w.Write([]byte(`{"id": "` + testMonitorID + `", "name": "monitor"}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.Error(w, "unexpected request", http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestSyntheticsClient(t, server, newTestHTTPClient(httpClientConfig{}))
+	if _, err := client.CreateMonitor(&synthetics.CreateMonitorArgs{Name: "monitor"}); err == nil || !strings.Contains(err.Error(), "code 200") {
+		t.Fatalf("expected a 200 on create to be rejected by default, got: %v", err)
+	}
+
+	statuses, err := parseAcceptedStatuses(map[string]interface{}{"POST": "200, 201"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	client = newTestSyntheticsClient(t, server, newTestHTTPClient(httpClientConfig{AcceptedStatuses: statuses}))
+	monitor, err := client.CreateMonitor(&synthetics.CreateMonitorArgs{Name: "monitor"})
+	if err != nil {
+		t.Fatalf("expected a 200 on create to be accepted when configured, got: %s", err)
+	}
+	if monitor.ID != testMonitorID {
+		t.Fatalf("expected the monitor to be created, got %#v", monitor)
+	}
+}
+
+func TestParseAcceptedStatuses(t *testing.T) {
+	for _, raw := range []map[string]interface{}{
+		{"DELETE": "200"},
+		{"POST": "302"},
+		{"PATCH": "ok"},
+	} {
+		if _, err := parseAcceptedStatuses(raw); err == nil {
+			t.Fatalf("expected %v to be invalid", raw)
+		}
+	}
+}
+
+func TestHTTPClientTruncatesLoggedBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("r", 100)))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	client := newTestHTTPClient(httpClientConfig{MaxLoggedBodyLength: 10})
+	request, err := http.NewRequest("PUT", server.URL, strings.NewReader(strings.Repeat("q", 100)))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	body, _ := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+
+	for _, want := range []string{"request body: qqqqqqqqqq...\n", "response body: rrrrrrrrrr...\n"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Fatalf("expected %q in logs:\n%s", want, logs.String())
+		}
+	}
+	if len(body) != 100 {
+		t.Fatalf("expected the response body to be passed through whole, got %d bytes", len(body))
+	}
+}
+
+func TestHTTPClientWarnsOfSlowRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	client := newTestHTTPClient(httpClientConfig{SlowRequestThreshold: 50 * time.Millisecond})
+	for _, path := range []string{"/fast", "/slow"} {
+		request, err := http.NewRequest("GET", server.URL+path, nil)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		response, err := client.Do(request)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		response.Body.Close()
+	}
+
+	if !strings.Contains(logs.String(), "[WARN] synthetics GET "+server.URL+"/slow took") {
+		t.Fatalf("expected a slow request warning for /slow in logs:\n%s", logs.String())
+	}
+	if strings.Contains(logs.String(), "[WARN] synthetics GET "+server.URL+"/fast") {
+		t.Fatalf("expected no slow request warning for /fast in logs:\n%s", logs.String())
+	}
+}
+
+func TestHTTPClientEmptyWriteResponses(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "PUT /synthetics/api/v3/monitors/" + testMonitorID + "/script":
+			w.WriteHeader(http.StatusNoContent)
+		case "PATCH /synthetics/api/v3/monitors/" + testMonitorID:
+			w.Write([]byte(body))
+		case "GET /synthetics/api/v3/monitors/" + testMonitorID:
+			w.Write([]byte(`{"id": "` + testMonitorID + `", "name": "renamed"}`))
+		case "DELETE /synthetics/api/v3/monitors/" + testMonitorID:
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.Error(w, "unexpected request", http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestSyntheticsClient(t, server, newTestHTTPClient(httpClientConfig{}))
+	if err := client.UpdateMonitorScript(testMonitorID, &synthetics.UpdateMonitorScriptArgs{ScriptText: "console.log('check');"}); err != nil {
+		t.Fatalf("expected a 204 script update to succeed, got: %s", err)
+	}
+	monitor, err := client.UpdateMonitor(testMonitorID, &synthetics.UpdateMonitorArgs{Name: "renamed"})
+	if err != nil {
+		t.Fatalf("expected an empty 200 update to succeed by default, got: %s", err)
+	}
+	if monitor.Name != "renamed" {
+		t.Fatalf("expected the updated monitor, got %#v", monitor)
+	}
+
+	body = `{"error": "not applied"}`
+	if _, err := client.UpdateMonitor(testMonitorID, &synthetics.UpdateMonitorArgs{Name: "renamed"}); err == nil || !strings.Contains(err.Error(), "code 200") {
+		t.Fatalf("expected a 200 update with a body not to be rewritten, got: %v", err)
+	}
+
+	// Only the writes in writeOperations are rewritten, so an empty
+	// 200 to a delete is still a failure.
+	if err := client.DeleteMonitor(testMonitorID); err == nil {
+		t.Fatal("expected an empty 200 delete to fail")
+	}
+}
@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// redactedValue replaces the values of sensitive fields in logs.
+const redactedValue = "[REDACTED]"
+
+// defaultRedactedFields are the JSON fields whose values are never
+// logged, in addition to any configured ones.
+var defaultRedactedFields = []string{"hmac"}
+
+// logBody logs a request or response body at debug level with the
+// values of sensitive fields redacted, truncated to maxLoggedBody.
+func (h *httpClient) logBody(kind string, request *http.Request, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	log.Printf("[DEBUG] synthetics %s %s %s body: %s", request.Method, request.URL, kind, truncateBody(redactJSON(body, h.redactedFields), h.maxLoggedBody))
+}
+
+// truncateBody shortens a body to at most max bytes, followed by an
+// ellipsis. A max of zero leaves the body whole.
+func truncateBody(body []byte, max uint) []byte {
+	if max == 0 || uint(len(body)) <= max {
+		return body
+	}
+	return append(body[:max:max], "..."...)
+}
+
+// redactJSON replaces the values of the given fields, at any depth,
+// in a JSON document. Field names are matched case-insensitively and
+// must be lowercase in fields. Documents that can't be parsed are
+// returned unchanged.
+func redactJSON(body []byte, fields map[string]bool) []byte {
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return body
+	}
+
+	redacted, err := json.Marshal(redactValue(document, fields))
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+func redactValue(v interface{}, fields map[string]bool) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if fields[strings.ToLower(key)] {
+				v[key] = redactedValue
+			} else {
+				v[key] = redactValue(value, fields)
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = redactValue(value, fields)
+		}
+	}
+	return v
+}
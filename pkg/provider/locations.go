@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"os"
+	"strings"
+
+	"github.com/dollarshaveclub/new-relic-synthetics-go/util"
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/pkg/errors"
+)
+
+// skipLocationValidationEnv is the environment variable that, when
+// set to any value, disables offline validation of monitor locations.
+// This is an escape hatch for when New Relic adds a location before
+// publicLocations is updated.
+const skipLocationValidationEnv = "NRS_SKIP_LOCATION_VALIDATION"
+
+// publicLocations are the codes of New Relic's public Synthetics
+// locations. An up to date list can be found at:
+// https://synthetics.newrelic.com/synthetics/api/v1/locations
+var publicLocations = map[string]bool{
+	"AWS_AF_SOUTH_1":     true,
+	"AWS_AP_EAST_1":      true,
+	"AWS_AP_NORTHEAST_1": true,
+	"AWS_AP_NORTHEAST_2": true,
+	"AWS_AP_SOUTH_1":     true,
+	"AWS_AP_SOUTHEAST_1": true,
+	"AWS_AP_SOUTHEAST_2": true,
+	"AWS_CA_CENTRAL_1":   true,
+	"AWS_EU_CENTRAL_1":   true,
+	"AWS_EU_NORTH_1":     true,
+	"AWS_EU_SOUTH_1":     true,
+	"AWS_EU_WEST_1":      true,
+	"AWS_EU_WEST_2":      true,
+	"AWS_EU_WEST_3":      true,
+	"AWS_ME_SOUTH_1":     true,
+	"AWS_SA_EAST_1":      true,
+	"AWS_US_EAST_1":      true,
+	"AWS_US_EAST_2":      true,
+	"AWS_US_WEST_1":      true,
+	"AWS_US_WEST_2":      true,
+}
+
+// validateLocation checks a monitor location against publicLocations
+// without calling the API. Locations are case-insensitive.
+func validateLocation(i interface{}, k string) ([]string, []error) {
+	if os.Getenv(skipLocationValidationEnv) != "" {
+		return nil, nil
+	}
+
+	location := i.(string)
+	if !publicLocations[strings.ToUpper(location)] {
+		return nil, []error{errors.Errorf(
+			"%s: unknown location %s. If it's a new New Relic location, set %s to skip this check",
+			k, location, skipLocationValidationEnv,
+		)}
+	}
+	return nil, nil
+}
+
+// normalizeLocations returns locations in the uppercase form the API
+// expects.
+func normalizeLocations(locations []interface{}) []string {
+	normalized := util.StrSlice(locations)
+	for i, location := range normalized {
+		normalized[i] = strings.ToUpper(location)
+	}
+	return normalized
+}
+
+// hashLocation hashes locations case-insensitively, so locations
+// that only differ by case are the same set element. Uppercase
+// locations hash the same as with schema.HashString, so existing
+// state is unaffected.
+func hashLocation(v interface{}) int {
+	return hashcode.String(strings.ToUpper(v.(string)))
+}
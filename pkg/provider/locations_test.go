@@ -0,0 +1,137 @@
+package provider
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// equalLocations reports whether two lists of locations hold the same
+// locations, in any order. New Relic doesn't keep the order locations
+// are sent in.
+func equalLocations(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return reflect.DeepEqual(a, b)
+}
+
+func TestEqualLocations(t *testing.T) {
+	for _, test := range []struct {
+		a, b  []string
+		equal bool
+	}{
+		{[]string{"AWS_US_EAST_1", "AWS_US_WEST_1"}, []string{"AWS_US_WEST_1", "AWS_US_EAST_1"}, true},
+		{[]string{}, nil, true},
+		{[]string{"AWS_US_EAST_1"}, []string{"AWS_US_EAST_1", "AWS_US_WEST_1"}, false},
+		{[]string{"AWS_US_EAST_1", "AWS_US_EAST_1"}, []string{"AWS_US_EAST_1", "AWS_US_WEST_1"}, false},
+		{[]string{"AWS_US_EAST_1", "AWS_US_WEST_1"}, []string{"AWS_US_EAST_1", "AWS_US_WEST_2"}, false},
+	} {
+		if got := equalLocations(test.a, test.b); got != test.equal {
+			t.Fatalf("expected equalLocations(%v, %v) to be %t", test.a, test.b, test.equal)
+		}
+	}
+	a := []string{"AWS_US_WEST_1", "AWS_US_EAST_1"}
+	equalLocations(a, []string{"AWS_US_EAST_1", "AWS_US_WEST_1"})
+	if a[0] != "AWS_US_WEST_1" {
+		t.Fatalf("expected the compared lists to be left alone, got %v", a)
+	}
+}
+
+func TestValidateLocation(t *testing.T) {
+	if _, errs := validateLocation("AWS_US_WEST_1", "locations"); len(errs) != 0 {
+		t.Fatalf("expected a known location to pass, got: %v", errs)
+	}
+	if _, errs := validateLocation("aws_us_west_1", "locations"); len(errs) != 0 {
+		t.Fatalf("expected a lowercase known location to pass, got: %v", errs)
+	}
+	if _, errs := validateLocation("AWS_MOON_1", "locations"); len(errs) == 0 {
+		t.Fatal("expected an unknown location to fail")
+	}
+
+	os.Setenv(skipLocationValidationEnv, "1")
+	defer os.Unsetenv(skipLocationValidationEnv)
+	if _, errs := validateLocation("AWS_MOON_1", "locations"); len(errs) != 0 {
+		t.Fatalf("expected validation to be skipped, got: %v", errs)
+	}
+}
+
+func TestNRSMonitorValidatesLocations(t *testing.T) {
+	for location, valid := range map[string]bool{
+		"AWS_US_WEST_1": true,
+		"AWS_MOON_1":    false,
+	} {
+		c, err := config.NewRawConfig(map[string]interface{}{
+			"name":      "monitor",
+			"type":      "SIMPLE",
+			"frequency": 10,
+			"locations": []interface{}{location},
+			"status":    "ENABLED",
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		_, es := Provider().ValidateResource("nrs_monitor", terraform.NewResourceConfig(c))
+		if valid && len(es) != 0 {
+			t.Fatalf("expected %s to be valid, got: %v", location, es)
+		}
+		if !valid && len(es) == 0 {
+			t.Fatalf("expected %s to be invalid", location)
+		}
+	}
+}
+
+func TestNRSMonitorLocationsCaseInsensitive(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.handleCreateMonitor(testMonitorID, `{
+		"id": "`+testMonitorID+`",
+		"name": "monitor",
+		"type": "SIMPLE",
+		"frequency": 10,
+		"uri": "https://example.com",
+		"locations": ["AWS_US_EAST_1", "AWS_US_WEST_1"],
+		"status": "ENABLED"
+	}`)
+
+	raw := map[string]interface{}{
+		"name":      "monitor",
+		"type":      "SIMPLE",
+		"frequency": 10,
+		"uri":       "https://example.com",
+		"locations": []interface{}{"aws_us_east_1", "Aws_Us_West_1"},
+		"status":    "ENABLED",
+	}
+	resourceData := schema.TestResourceDataRaw(t, NRSMonitorResource().Schema, raw)
+	if err := NRSMonitorCreate(resourceData, fake.Meta(t)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var body struct {
+		Locations []string `json:"locations"`
+	}
+	if err := json.Unmarshal([]byte(fake.Requests("POST", "/synthetics/api/v3/monitors")[0].Body), &body); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !equalLocations(body.Locations, []string{"AWS_US_EAST_1", "AWS_US_WEST_1"}) {
+		t.Fatalf("expected uppercase locations to be sent, got: %v", body.Locations)
+	}
+
+	if err := NRSMonitorRead(resourceData, fake.Meta(t)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	diff := planResource(t, NRSMonitorResource(), resourceData.State(), raw)
+	if !diff.Empty() {
+		t.Fatalf("expected differently cased locations to produce an empty plan, got: %#v", diff.Attributes)
+	}
+}
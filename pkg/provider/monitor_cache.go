@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"sync"
+
+	synthetics "github.com/dollarshaveclub/new-relic-synthetics-go"
+	"github.com/dollarshaveclub/new-relic-synthetics-go/util"
+	"github.com/pkg/errors"
+)
+
+// maxMonitorPageSize is the largest number of monitors the API
+// returns per page when listing monitors.
+const maxMonitorPageSize = 100
+
+// monitorCache serves monitor reads from a single listing of all
+// monitors, loaded on first use. Monitors may be stale for the
+// lifetime of the cache, so writes must invalidate the monitors they
+// touch.
+type monitorCache struct {
+	client   *synthetics.Client
+	pageSize uint
+
+	load     sync.Once
+	loadErr  error
+	mu       sync.Mutex
+	monitors map[string]*synthetics.Monitor
+}
+
+func newMonitorCache(client *synthetics.Client, pageSize uint) *monitorCache {
+	return &monitorCache{client: client, pageSize: pageSize}
+}
+
+// Get returns a monitor. Monitors that aren't in the listing, or
+// have been invalidated, are fetched from the API.
+func (c *monitorCache) Get(id string) (*synthetics.Monitor, error) {
+	c.load.Do(func() {
+		c.loadErr = c.loadMonitors()
+	})
+	if c.loadErr != nil {
+		return nil, c.loadErr
+	}
+
+	c.mu.Lock()
+	monitor, ok := c.monitors[id]
+	c.mu.Unlock()
+	if ok {
+		return monitor, nil
+	}
+
+	return c.client.GetMonitor(id)
+}
+
+// Invalidate drops a monitor from the cache.
+func (c *monitorCache) Invalidate(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.monitors, id)
+}
+
+func (c *monitorCache) loadMonitors() error {
+	monitors, err := getAllMonitorsMap(c.client, c.pageSize)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.monitors = make(map[string]*synthetics.Monitor, len(monitors))
+	for id, monitor := range monitors {
+		c.monitors[id] = monitorFromExtended(monitor)
+	}
+
+	return nil
+}
+
+// getAllMonitors pages through every monitor in the account,
+// requesting pageSize monitors at a time.
+func getAllMonitors(client *synthetics.Client, pageSize uint) ([]*synthetics.ExtendedMonitor, error) {
+	var monitors []*synthetics.ExtendedMonitor
+	for {
+		response, err := client.GetAllMonitors(uint(len(monitors)), pageSize)
+		if err != nil {
+			return nil, errors.Wrap(err, "error: could not list monitors")
+		}
+		monitors = append(monitors, response.Monitors...)
+
+		if len(response.Monitors) == 0 || uint(len(monitors)) >= response.Count {
+			return monitors, nil
+		}
+	}
+}
+
+// getAllMonitorsMap pages through every monitor in the account and
+// returns them keyed by ID.
+func getAllMonitorsMap(client *synthetics.Client, pageSize uint) (map[string]*synthetics.ExtendedMonitor, error) {
+	monitors, err := getAllMonitors(client, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	monitorsByID := make(map[string]*synthetics.ExtendedMonitor, len(monitors))
+	for _, monitor := range monitors {
+		if _, ok := monitorsByID[monitor.ID]; ok {
+			return nil, errors.Errorf("error: duplicate monitor ID in listing: %s", monitor.ID)
+		}
+		monitorsByID[monitor.ID] = monitor
+	}
+
+	return monitorsByID, nil
+}
+
+// monitorFromExtended converts a monitor returned by GetAllMonitors
+// to the format returned by GetMonitor.
+func monitorFromExtended(e *synthetics.ExtendedMonitor) *synthetics.Monitor {
+	monitor := &synthetics.Monitor{
+		ID:           e.ID,
+		Name:         e.Name,
+		Type:         e.Type,
+		Frequency:    e.Frequency,
+		URI:          e.URI,
+		Locations:    e.Locations,
+		Status:       e.Status,
+		SLAThreshold: e.SLAThreshold,
+		UserID:       e.UserID,
+		APIVersion:   e.APIVersion,
+		Options:      e.Options,
+	}
+
+	if v, ok := e.Options["validationString"].(string); ok {
+		monitor.ValidationString = util.StrPtr(v)
+	}
+	if v, ok := e.Options["verifySSL"].(bool); ok {
+		monitor.VerifySSL = util.BoolPtr(v)
+	}
+	if v, ok := e.Options["bypassHEADRequest"].(bool); ok {
+		monitor.BypassHEADRequest = util.BoolPtr(v)
+	}
+	if v, ok := e.Options["treatRedirectAsFailure"].(bool); ok {
+		monitor.TreatRedirectAsFailure = util.BoolPtr(v)
+	}
+
+	return monitor
+}
@@ -0,0 +1,155 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestMonitorCacheServesReadsFromOneListing(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.Handle("GET", "/synthetics/api/v3/monitors", jsonResponse(http.StatusOK, `{
+		"count": 3,
+		"monitors": [
+			{"id": "monitor-1", "name": "one", "type": "SIMPLE", "frequency": 10, "locations": ["AWS_US_WEST_1"], "status": "ENABLED", "options": {"verifySSL": true}, "createdAt": "2017-06-05T15:52:57.830+0000", "modifiedAt": "2017-06-05T15:52:57.830+0000"},
+			{"id": "monitor-2", "name": "two", "type": "SIMPLE", "frequency": 10, "locations": ["AWS_US_WEST_1"], "status": "ENABLED", "createdAt": "2017-06-05T15:52:57.830+0000", "modifiedAt": "2017-06-05T15:52:57.830+0000"},
+			{"id": "monitor-3", "name": "three", "type": "SIMPLE", "frequency": 10, "locations": ["AWS_US_WEST_1"], "status": "ENABLED", "createdAt": "2017-06-05T15:52:57.830+0000", "modifiedAt": "2017-06-05T15:52:57.830+0000"}
+		]
+	}`))
+
+	meta := fake.Meta(t)
+	meta.monitors = newMonitorCache(meta.client, maxMonitorPageSize)
+
+	for i := 1; i <= 3; i++ {
+		id := fmt.Sprintf("monitor-%d", i)
+		resourceData := NRSMonitorResource().Data(&terraform.InstanceState{ID: id})
+		if err := NRSMonitorRead(resourceData, meta); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if resourceData.Get("frequency").(int) != 10 {
+			t.Fatalf("expected monitor %s to be read from the cache", id)
+		}
+	}
+
+	if got := len(fake.Requests("GET", "/synthetics/api/v3/monitors")); got != 1 {
+		t.Fatalf("expected 1 list request, got %d", got)
+	}
+	for i := 1; i <= 3; i++ {
+		if got := len(fake.Requests("GET", monitorPath(fmt.Sprintf("monitor-%d", i)))); got != 0 {
+			t.Fatalf("expected no GetMonitor requests, got %d", got)
+		}
+	}
+}
+
+func TestMonitorCacheInvalidate(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.Handle("GET", "/synthetics/api/v3/monitors", jsonResponse(http.StatusOK, `{
+		"count": 1,
+		"monitors": [
+			{"id": "monitor-1", "name": "stale", "type": "SIMPLE", "frequency": 10, "locations": ["AWS_US_WEST_1"], "status": "ENABLED", "createdAt": "2017-06-05T15:52:57.830+0000", "modifiedAt": "2017-06-05T15:52:57.830+0000"}
+		]
+	}`))
+	fake.Handle("GET", monitorPath("monitor-1"), jsonResponse(http.StatusOK, `{
+		"id": "monitor-1", "name": "fresh", "type": "SIMPLE", "frequency": 10, "locations": ["AWS_US_WEST_1"], "status": "ENABLED"
+	}`))
+
+	cache := newMonitorCache(fake.Client(t), maxMonitorPageSize)
+	monitor, err := cache.Get("monitor-1")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if monitor.Name != "stale" {
+		t.Fatalf("expected the cached monitor, got %q", monitor.Name)
+	}
+
+	cache.Invalidate("monitor-1")
+	monitor, err = cache.Get("monitor-1")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if monitor.Name != "fresh" {
+		t.Fatalf("expected the monitor to be fetched from the API, got %q", monitor.Name)
+	}
+}
+
+func TestGetAllMonitorsMap(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.Handle("GET", "/synthetics/api/v3/monitors", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("offset") {
+		case "":
+			jsonResponse(http.StatusOK, `{"count": 3, "monitors": [
+				{"id": "monitor-1", "name": "one", "createdAt": "2017-06-05T15:52:57.830+0000", "modifiedAt": "2017-06-05T15:52:57.830+0000"},
+				{"id": "monitor-2", "name": "two", "createdAt": "2017-06-05T15:52:57.830+0000", "modifiedAt": "2017-06-05T15:52:57.830+0000"}
+			]}`)(w, r)
+		case "2":
+			jsonResponse(http.StatusOK, `{"count": 3, "monitors": [
+				{"id": "monitor-3", "name": "three", "createdAt": "2017-06-05T15:52:57.830+0000", "modifiedAt": "2017-06-05T15:52:57.830+0000"}
+			]}`)(w, r)
+		default:
+			t.Fatalf("unexpected offset: %s", r.URL.Query().Get("offset"))
+		}
+	})
+
+	monitors, err := getAllMonitorsMap(fake.Client(t), maxMonitorPageSize)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]string{"monitor-1": "one", "monitor-2": "two", "monitor-3": "three"}
+	if len(monitors) != len(expected) {
+		t.Fatalf("expected %d monitors, got %d", len(expected), len(monitors))
+	}
+	for id, name := range expected {
+		monitor, ok := monitors[id]
+		if !ok || monitor.ID != id || monitor.Name != name {
+			t.Fatalf("expected monitor %s named %s, got: %#v", id, name, monitor)
+		}
+	}
+}
+
+func TestGetAllMonitorsMapDuplicateID(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.Handle("GET", "/synthetics/api/v3/monitors", jsonResponse(http.StatusOK, `{"count": 2, "monitors": [
+		{"id": "monitor-1", "name": "one", "createdAt": "2017-06-05T15:52:57.830+0000", "modifiedAt": "2017-06-05T15:52:57.830+0000"},
+		{"id": "monitor-1", "name": "two", "createdAt": "2017-06-05T15:52:57.830+0000", "modifiedAt": "2017-06-05T15:52:57.830+0000"}
+	]}`))
+
+	if _, err := getAllMonitorsMap(fake.Client(t), maxMonitorPageSize); err == nil {
+		t.Fatal("expected an error for a duplicate monitor ID")
+	}
+}
+
+func TestGetAllMonitorsPageSize(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.Handle("GET", "/synthetics/api/v3/monitors", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("limit"); got != "2" {
+			t.Fatalf("expected a page size of 2, got %q", got)
+		}
+		switch r.URL.Query().Get("offset") {
+		case "":
+			jsonResponse(http.StatusOK, `{"count": 3, "monitors": [
+				{"id": "monitor-1", "createdAt": "2017-06-05T15:52:57.830+0000", "modifiedAt": "2017-06-05T15:52:57.830+0000"},
+				{"id": "monitor-2", "createdAt": "2017-06-05T15:52:57.830+0000", "modifiedAt": "2017-06-05T15:52:57.830+0000"}
+			]}`)(w, r)
+		case "2":
+			jsonResponse(http.StatusOK, `{"count": 3, "monitors": [
+				{"id": "monitor-3", "createdAt": "2017-06-05T15:52:57.830+0000", "modifiedAt": "2017-06-05T15:52:57.830+0000"}
+			]}`)(w, r)
+		default:
+			t.Fatalf("unexpected offset: %s", r.URL.Query().Get("offset"))
+		}
+	})
+
+	monitors, err := getAllMonitors(fake.Client(t), 2)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(monitors) != 3 {
+		t.Fatalf("expected 3 monitors, got %d", len(monitors))
+	}
+	if got := len(fake.Requests("GET", "/synthetics/api/v3/monitors")); got != 2 {
+		t.Fatalf("expected 2 page requests, got %d", got)
+	}
+}
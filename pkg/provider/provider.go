@@ -1,10 +1,20 @@
 package provider
 
 import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
 	synthetics "github.com/dollarshaveclub/new-relic-synthetics-go"
+	"github.com/dollarshaveclub/new-relic-synthetics-go/util"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
 )
 
 // Provider returns a new New Relic Synthetics Terraform provider.
@@ -18,28 +28,276 @@ func Provider() terraform.ResourceProvider {
 				Sensitive:   true,
 				DefaultFunc: schema.EnvDefaultFunc("NEWRELIC_API_KEY", "key"),
 			},
+			"account_id": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "The New Relic account ID, used to link to monitors in the New Relic UI",
+				ValidateFunc: validation.IntBetween(0, math.MaxInt32),
+			},
+			"region": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "US",
+				Description:  "The New Relic region of the account, US or EU, used to link to monitors in the New Relic UI",
+				ValidateFunc: validation.StringInSlice([]string{"US", "EU"}, false),
+			},
+			"cache_monitors": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Serve monitor reads from a single listing of all monitors",
+			},
+			"monitor_page_size": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      maxMonitorPageSize,
+				Description:  "The number of monitors requested per page when listing monitors",
+				ValidateFunc: validation.IntBetween(1, maxMonitorPageSize),
+			},
+			"monitor_quota": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "The account's monitor limit. When set, monitors aren't created once the account has this many",
+				ValidateFunc: validation.IntBetween(0, math.MaxInt32),
+			},
+			"max_retries": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3,
+				Description:  "The number of times to retry idempotent requests that fail transiently",
+				ValidateFunc: validation.IntBetween(0, math.MaxInt32),
+			},
+			"retry_create": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Retry monitor and alert condition creation on transient failures too, at the risk of creating duplicates",
+			},
+			"max_concurrent_requests": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "The maximum number of requests to New Relic in flight at once, or 0 for no limit",
+				ValidateFunc: validation.IntBetween(0, math.MaxInt32),
+			},
+			"connect_timeout": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      10,
+				Description:  "The timeout in seconds for connecting to New Relic, including the TLS handshake",
+				ValidateFunc: validation.IntBetween(1, math.MaxInt32),
+			},
+			"request_timeout": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      60,
+				Description:  "The timeout in seconds for an entire request to New Relic",
+				ValidateFunc: validation.IntBetween(1, math.MaxInt32),
+			},
+			"accepted_statuses": &schema.Schema{
+				Type:         schema.TypeMap,
+				Optional:     true,
+				Description:  "Comma-separated 2xx statuses accepted as success for monitor creation (POST), updates (PATCH), and script updates (PUT), in addition to the statuses New Relic documents",
+				Elem:         schema.TypeString,
+				ValidateFunc: validateAcceptedStatuses,
+			},
+			"slow_request_threshold": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "Requests to New Relic taking longer than this many seconds are logged as slow, or 0 to disable the warning",
+				ValidateFunc: validation.IntBetween(0, math.MaxInt32),
+			},
+			"min_tls_version": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "1.2",
+				Description:  "The lowest TLS version used to connect to New Relic, one of 1.0, 1.1, 1.2, or 1.3",
+				ValidateFunc: validation.StringInSlice([]string{"1.0", "1.1", "1.2", "1.3"}, false),
+			},
+			"default_frequency": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      10,
+				Description:  "The checking frequency in minutes of monitors that don't set one",
+				ValidateFunc: validateFrequency,
+			},
+			"log_max_body_length": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "The number of bytes of request and response bodies logged, or 0 for no limit",
+				ValidateFunc: validation.IntBetween(0, math.MaxInt32),
+			},
+			"log_redacted_fields": &schema.Schema{
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "JSON fields whose values are redacted from logged request and response bodies",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
 		},
 		ConfigureFunc: getClient,
 		ResourcesMap: map[string]*schema.Resource{
 			"nrs_monitor":         NRSMonitorResource(),
 			"nrs_alert_condition": NRSAlertConditionResource(),
 		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"nrs_monitors": NRSMonitorsDataSource(),
+		},
+	}
+}
+
+// providerMeta is passed to resources as their meta argument.
+type providerMeta struct {
+	client *synthetics.Client
+
+	// httpClient is the HTTP client used by client. It's nil when
+	// the synthetics client was built with its default one.
+	httpClient *httpClient
+
+	// defaultFrequency is the frequency of monitors created without
+	// one.
+	defaultFrequency int
+
+	// accountID is the account's ID and uiHost the host of the New
+	// Relic Synthetics UI for its region, which monitor_url links to.
+	// accountID is zero if it isn't configured.
+	accountID int
+	uiHost    string
+
+	// monitorPageSize is the number of monitors requested per page
+	// when listing monitors.
+	monitorPageSize uint
+
+	// monitorQuota is the number of monitors the account may have,
+	// or zero if it isn't checked.
+	monitorQuota uint
+
+	// monitors caches monitor reads. It's nil unless monitor
+	// caching is enabled.
+	monitors *monitorCache
+
+	// reads deduplicates concurrent reads of the same monitor or
+	// script, such as during a large refresh.
+	reads singleflight.Group
+}
+
+// uiHosts are the hosts of the New Relic Synthetics UI by region.
+var uiHosts = map[string]string{
+	"US": "synthetics.newrelic.com",
+	"EU": "synthetics.eu.newrelic.com",
+}
+
+// monitorURL returns the URL of a monitor in the New Relic UI, or an
+// empty string if the account ID it needs isn't configured.
+func (m *providerMeta) monitorURL(id string) string {
+	if m.accountID == 0 {
+		return ""
+	}
+	return fmt.Sprintf("https://%s/accounts/%d/monitors/%s", m.uiHost, m.accountID, id)
+}
+
+// logRequestCounts logs the number of requests made to New Relic so
+// far, by method and status, after an operation on a resource.
+func (m *providerMeta) logRequestCounts(operation string) {
+	if m.httpClient == nil {
+		return
+	}
+
+	var counts []string
+	for key, count := range m.httpClient.RequestCounts() {
+		counts = append(counts, fmt.Sprintf("%s: %d", key, count))
+	}
+	sort.Strings(counts)
+	log.Printf("[DEBUG] synthetics requests after %s: %s", operation, strings.Join(counts, ", "))
+}
+
+// getMonitor returns a monitor, using the monitor cache if it's
+// enabled. Concurrent calls for the same monitor share one request.
+func (m *providerMeta) getMonitor(id string) (*synthetics.Monitor, error) {
+	monitor, err, _ := m.reads.Do("monitor/"+id, func() (interface{}, error) {
+		if m.monitors != nil {
+			return m.monitors.Get(id)
+		}
+		return m.client.GetMonitor(id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return monitor.(*synthetics.Monitor), nil
+}
+
+// getMonitorScript returns a monitor's script. Concurrent calls for
+// the same monitor share one request.
+func (m *providerMeta) getMonitorScript(id string) (string, error) {
+	script, err, _ := m.reads.Do("script/"+id, func() (interface{}, error) {
+		return m.client.GetMonitorScript(id)
+	})
+	if err != nil {
+		return "", err
 	}
+	return script.(string), nil
 }
 
+// invalidateMonitor drops a monitor from the monitor cache so that
+// subsequent reads go to the API. It must be called after writes.
+func (m *providerMeta) invalidateMonitor(id string) {
+	if m.monitors != nil {
+		m.monitors.Invalidate(id)
+	}
+}
+
+// getClient configures the provider. It's called once, and the
+// returned meta, along with the clients and cache it holds, is shared
+// by every resource and data source.
 func getClient(rd *schema.ResourceData) (interface{}, error) {
 	apiKey, ok := rd.Get("newrelic_api_key").(string)
 	if !ok {
 		return nil, errors.New("invalid type for new relic api key")
 	}
 
+	acceptedStatuses, err := parseAcceptedStatuses(rd.Get("accepted_statuses").(map[string]interface{}))
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := newHTTPClient(httpClientConfig{
+		Retries:        uint(rd.Get("max_retries").(int)),
+		RetryCreate:    rd.Get("retry_create").(bool),
+		ConnectTimeout: time.Duration(rd.Get("connect_timeout").(int)) * time.Second,
+		RequestTimeout: time.Duration(rd.Get("request_timeout").(int)) * time.Second,
+		MinTLSVersion:  tlsVersions[rd.Get("min_tls_version").(string)],
+		RedactedFields: util.StrSlice(rd.Get("log_redacted_fields").([]interface{})),
+
+		AcceptedStatuses:      acceptedStatuses,
+		MaxConcurrentRequests: uint(rd.Get("max_concurrent_requests").(int)),
+		MaxLoggedBodyLength:   uint(rd.Get("log_max_body_length").(int)),
+		SlowRequestThreshold:  time.Duration(rd.Get("slow_request_threshold").(int)) * time.Second,
+	})
+
 	conf := func(s *synthetics.Client) {
 		s.APIKey = apiKey
+		s.HTTPClient = httpClient
 	}
 	client, err := synthetics.NewClient(conf)
 	if err != nil {
 		return nil, errors.Wrap(err, "error: could not instantiate synthetics client")
 	}
 
-	return client, nil
+	meta := &providerMeta{
+		client:           client,
+		httpClient:       httpClient,
+		defaultFrequency: rd.Get("default_frequency").(int),
+		accountID:        rd.Get("account_id").(int),
+		uiHost:           uiHosts[rd.Get("region").(string)],
+		monitorPageSize:  uint(rd.Get("monitor_page_size").(int)),
+		monitorQuota:     uint(rd.Get("monitor_quota").(int)),
+	}
+	if rd.Get("cache_monitors").(bool) {
+		meta.monitors = newMonitorCache(client, meta.monitorPageSize)
+	}
+
+	return meta, nil
 }
@@ -4,7 +4,9 @@ import (
 	"testing"
 
 	"github.com/dollarshaveclub/terraform-provider-nrs/pkg/provider"
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
 )
 
 func TestProvider(t *testing.T) {
@@ -12,3 +14,74 @@ func TestProvider(t *testing.T) {
 		t.Fatalf("err: %s", err)
 	}
 }
+
+func TestProviderSharesMeta(t *testing.T) {
+	var resourceMeta, dataSourceMeta interface{}
+
+	p := provider.Provider().(*schema.Provider)
+	p.ResourcesMap["nrs_test"] = &schema.Resource{
+		Schema: map[string]*schema.Schema{},
+		Create: func(d *schema.ResourceData, meta interface{}) error {
+			resourceMeta = meta
+			d.SetId("test")
+			return nil
+		},
+		Read:   func(*schema.ResourceData, interface{}) error { return nil },
+		Delete: func(*schema.ResourceData, interface{}) error { return nil },
+	}
+	p.DataSourcesMap = map[string]*schema.Resource{
+		"nrs_test": &schema.Resource{
+			Schema: map[string]*schema.Schema{},
+			Read: func(d *schema.ResourceData, meta interface{}) error {
+				dataSourceMeta = meta
+				d.SetId("test")
+				return nil
+			},
+		},
+	}
+
+	c, err := config.NewRawConfig(map[string]interface{}{
+		"newrelic_api_key": "test-api-key",
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := p.Configure(terraform.NewResourceConfig(c)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	info := &terraform.InstanceInfo{Type: "nrs_test"}
+	if _, err := p.Apply(info, nil, &terraform.InstanceDiff{}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := p.ReadDataApply(info, &terraform.InstanceDiff{}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if resourceMeta == nil || resourceMeta != p.Meta() {
+		t.Fatalf("expected resources to receive the configured meta, got: %#v", resourceMeta)
+	}
+	if dataSourceMeta != resourceMeta {
+		t.Fatalf("expected data sources to receive the same meta as resources, got: %#v", dataSourceMeta)
+	}
+}
+
+func TestProviderValidatesRetriesAndTimeouts(t *testing.T) {
+	for attribute, value := range map[string]interface{}{
+		"max_retries":     -1,
+		"connect_timeout": 0,
+		"request_timeout": -1,
+	} {
+		c, err := config.NewRawConfig(map[string]interface{}{
+			"newrelic_api_key": "test-api-key",
+			attribute:          value,
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		_, errs := provider.Provider().Validate(terraform.NewResourceConfig(c))
+		if len(errs) == 0 {
+			t.Fatalf("expected %s = %v to be invalid", attribute, value)
+		}
+	}
+}
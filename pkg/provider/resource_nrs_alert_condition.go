@@ -58,7 +58,8 @@ func NRSAlertConditionResource() *schema.Resource {
 // NRSAlertConditionCreate creates a Synthetics alert condition using
 // Terraform configuration.
 func NRSAlertConditionCreate(resourceData *schema.ResourceData, meta interface{}) error {
-	client := meta.(*synthetics.Client)
+	client := meta.(*providerMeta).client
+	defer meta.(*providerMeta).logRequestCounts("creating an alert condition")
 
 	args := &synthetics.CreateAlertConditionArgs{
 		Name:      resourceData.Get("name").(string),
@@ -82,10 +83,10 @@ func NRSAlertConditionCreate(resourceData *schema.ResourceData, meta interface{}
 // NRSAlertConditionExists checks whether an alert condition exists
 // using Terraform configuration.
 func NRSAlertConditionExists(resourceData *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(*synthetics.Client)
+	client := meta.(*providerMeta).client
 
 	_, err := client.GetAlertCondition(uint(resourceData.Get("policy_id").(int)), uint(resourceData.Get("id").(int)))
-	if err == synthetics.ErrAlertConditionNotFound {
+	if isNotFound(err) {
 		return false, nil
 	}
 	if err != nil {
@@ -98,7 +99,8 @@ func NRSAlertConditionExists(resourceData *schema.ResourceData, meta interface{}
 // NRSAlertConditionDelete deletes a Synthetics alert condition using
 // Terraform configuration.
 func NRSAlertConditionDelete(resourceData *schema.ResourceData, meta interface{}) error {
-	client := meta.(*synthetics.Client)
+	client := meta.(*providerMeta).client
+	defer meta.(*providerMeta).logRequestCounts("deleting an alert condition")
 
 	if err := client.DeleteAlertCondition(uint(resourceData.Get("id").(int))); err != nil {
 		return errors.Wrap(err, "error: could not delete alert condition")
@@ -110,7 +112,7 @@ func NRSAlertConditionDelete(resourceData *schema.ResourceData, meta interface{}
 // NRSAlertConditionRead refreshes alert condition information using
 // Terraform configuration.
 func NRSAlertConditionRead(resourceData *schema.ResourceData, meta interface{}) error {
-	client := meta.(*synthetics.Client)
+	client := meta.(*providerMeta).client
 
 	ac, err := client.GetAlertCondition(uint(resourceData.Get("policy_id").(int)), uint(resourceData.Get("id").(int)))
 	if err != nil {
@@ -136,7 +138,8 @@ func NRSAlertConditionRead(resourceData *schema.ResourceData, meta interface{})
 // NRSAlertConditionUpdate updates a Synthetics alert condition using
 // Terraform configuration.
 func NRSAlertConditionUpdate(resourceData *schema.ResourceData, meta interface{}) error {
-	client := meta.(*synthetics.Client)
+	client := meta.(*providerMeta).client
+	defer meta.(*providerMeta).logRequestCounts("updating an alert condition")
 
 	args := &synthetics.UpdateAlertConditionArgs{
 		Name:      resourceData.Get("name").(string),
@@ -2,6 +2,17 @@ package provider
 
 import (
 	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	synthetics "github.com/dollarshaveclub/new-relic-synthetics-go"
 	"github.com/dollarshaveclub/new-relic-synthetics-go/util"
@@ -21,24 +32,37 @@ func NRSMonitorResource() *schema.Resource {
 				Description: "The monitor's ID with New Relic",
 			},
 			"name": &schema.Schema{
-				Type:     schema.TypeString,
-				Required: true,
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringLenBetween(1, maxMonitorNameLength),
 			},
 			"frequency": &schema.Schema{
-				Type:        schema.TypeInt,
-				Required:    true,
-				Description: "The monitor's checking frequency in minutes (one of 1, 5, 10, 15, 30, 60, 360, 720, or 1440)",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				Description:  "The monitor's checking frequency in minutes (one of 1, 5, 10, 15, 30, 60, 360, 720, or 1440). Defaults to the provider's default_frequency",
+				ValidateFunc: validateFrequency,
 			},
-			"uri": &schema.Schema{
+			"frequency_human": &schema.Schema{
 				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "The URL to monitor",
+				Description: "The checking frequency in words, like \"every 5 minutes\"",
+				Computed:    true,
+			},
+			"uri": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "The URL to monitor",
+				ValidateFunc: validateURI,
 			},
 			"locations": &schema.Schema{
 				Type:        schema.TypeSet,
-				Required:    true,
-				Description: "The locations to check from",
-				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+				Description: "The public locations to check from. Script monitors may use script_locations instead",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateLocation,
+				},
+				Set: hashLocation,
 			},
 			"status": &schema.Schema{
 				Type:         schema.TypeString,
@@ -49,39 +73,81 @@ func NRSMonitorResource() *schema.Resource {
 			},
 			"sla_threshold": &schema.Schema{
 				Type:        schema.TypeFloat,
-				Description: "The monitor's SLA threshold",
+				Description: "The monitor's SLA threshold, rounded to two decimal places",
 				Optional:    true,
 				Computed:    true,
+				StateFunc:   slaThresholdStateFunc,
 			},
-			"validation_string": &schema.Schema{
-				Type:        schema.TypeString,
-				Description: "The monitor's validation string",
+			"options": &schema.Schema{
+				Type:        schema.TypeList,
+				Description: "The monitor's options",
 				Optional:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"validation_string": &schema.Schema{
+							Type:         schema.TypeString,
+							Description:  "The monitor's validation string",
+							Optional:     true,
+							ValidateFunc: validation.StringLenBetween(0, maxValidationStringLength),
+						},
+						"verify_ssl": &schema.Schema{
+							Type:        schema.TypeBool,
+							Description: "Verify SSL",
+							Optional:    true,
+						},
+						"bypass_head_request": &schema.Schema{
+							Type:        schema.TypeBool,
+							Description: "Bypass HEAD request",
+							Optional:    true,
+						},
+						"treat_redirect_as_failure": &schema.Schema{
+							Type:        schema.TypeBool,
+							Description: "Treat redirect as failure",
+							Optional:    true,
+						},
+					},
+				},
+			},
+			"validation_string": &schema.Schema{
+				Type:          schema.TypeString,
+				Description:   "The monitor's validation string",
+				Optional:      true,
+				ValidateFunc:  validation.StringLenBetween(0, maxValidationStringLength),
+				Deprecated:    "Use options.validation_string instead",
+				ConflictsWith: []string{"options"},
 			},
 			"verify_ssl": &schema.Schema{
-				Type:        schema.TypeBool,
-				Description: "Verify SSL",
-				Optional:    true,
+				Type:          schema.TypeBool,
+				Description:   "Verify SSL",
+				Optional:      true,
+				Deprecated:    "Use options.verify_ssl instead",
+				ConflictsWith: []string{"options"},
 			},
 			"bypass_head_request": &schema.Schema{
-				Type:        schema.TypeBool,
-				Description: "Bypass HEAD request",
-				Optional:    true,
+				Type:          schema.TypeBool,
+				Description:   "Bypass HEAD request",
+				Optional:      true,
+				Deprecated:    "Use options.bypass_head_request instead",
+				ConflictsWith: []string{"options"},
 			},
 			"treat_redirect_as_failure": &schema.Schema{
-				Type:        schema.TypeBool,
-				Description: "Treat redirect as failure",
-				Optional:    true,
+				Type:          schema.TypeBool,
+				Description:   "Treat redirect as failure",
+				Optional:      true,
+				Deprecated:    "Use options.treat_redirect_as_failure instead",
+				ConflictsWith: []string{"options"},
 			},
 			"script": &schema.Schema{
-				Type:        schema.TypeString,
-				Description: "The script to execute",
-				Optional:    true,
-				StateFunc:   sha256StateFunc,
+				Type:         schema.TypeString,
+				Description:  "The script to execute",
+				Optional:     true,
+				StateFunc:    sha256StateFunc,
+				ValidateFunc: validateScript,
 			},
 			"script_locations": &schema.Schema{
 				Type:        schema.TypeList,
-				Description: "The private locations to execute the script from",
+				Description: "The private locations to execute the script from. New Relic doesn't return them, so they're set on the first apply after an import",
 				Optional:    true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
@@ -95,17 +161,75 @@ func NRSMonitorResource() *schema.Resource {
 							Description: "The HMAC for the private location",
 							Optional:    true,
 						},
+						"hmac_file": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "A file to read the HMAC for the private location from at apply time, instead of hmac. Only the path and a hash of the HMAC are stored in state",
+							Optional:    true,
+						},
+						"hmac_file_hash": &schema.Schema{
+							Type:        schema.TypeString,
+							Description: "A SHA-256 hash of the HMAC last sent from hmac_file, used to detect changes to the file",
+							Computed:    true,
+						},
 					},
 				},
 			},
+			"has_script": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether New Relic has a script for the monitor. It isn't read while manage_script is false",
+				Computed:    true,
+			},
+			"manage_script": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether to manage the script of script monitors. When false, script and script_locations are ignored",
+				Optional:    true,
+				Default:     true,
+			},
 			"type": &schema.Schema{
 				Type:         schema.TypeString,
 				Description:  "The type of monitor (one of SIMPLE, BROWSER, SCRIPT_API, SCRIPT_BROWSER)",
 				Required:     true,
 				ForceNew:     true,
-				ValidateFunc: validation.StringInSlice([]string{"SIMPLE", "BROWSER", "SCRIPT_API", "SCRIPT_BROWSER"}, false),
+				ValidateFunc: monitorTypeValidator(deprecatedMonitorTypes),
+			},
+			"api_version": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The API version the monitor runs with",
+				Computed:    true,
+			},
+			"monitor_url": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "The URL of the monitor in the New Relic UI, if the provider's account_id is set",
+				Computed:    true,
+			},
+			"options_json": &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "All of the monitor's options as New Relic stores them, as JSON",
+				Computed:    true,
+			},
+			"alert_policy_ids": &schema.Schema{
+				Type:        schema.TypeSet,
+				Description: "The IDs of alert policies to attach the monitor to with an alert condition each",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+			},
+			"alert_condition_ids": &schema.Schema{
+				Type:        schema.TypeMap,
+				Description: "The IDs of the alert conditions attaching the monitor to its alert policies, by policy ID",
+				Computed:    true,
+			},
+			"wait_for_delete": &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Wait on destroy until New Relic no longer returns the monitor",
+				Optional:    true,
 			},
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Importer: &schema.ResourceImporter{
+			State: NRSMonitorImport,
+		},
 		Create: NRSMonitorCreate,
 		Exists: NRSMonitorExists,
 		Delete: NRSMonitorDelete,
@@ -114,159 +238,674 @@ func NRSMonitorResource() *schema.Resource {
 	}
 }
 
+// monitorIDPattern matches the UUIDs New Relic uses as monitor IDs.
+var monitorIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// maxMonitorNameLength is the longest monitor name New Relic
+// accepts.
+const maxMonitorNameLength = 255
+
+// maxValidationStringLength is the longest validation string New
+// Relic accepts.
+const maxValidationStringLength = 4096
+
+// maxScriptSize is the largest script New Relic accepts, in bytes,
+// once base64 encoded for the API.
+const maxScriptSize = 100 * 1024
+
+// monitorTypes are the monitor types the resource supports.
+var monitorTypes = []string{
+	synthetics.TypeSimple,
+	synthetics.TypeBrowser,
+	synthetics.TypeScriptAPI,
+	synthetics.TypeScriptBrowser,
+}
+
+// deprecatedMonitorTypes maps monitor types that New Relic has
+// deprecated to advice on what to use instead. None of monitorTypes
+// is deprecated yet; entries are added here when New Relic announces
+// a deprecation.
+var deprecatedMonitorTypes = map[string]string{}
+
+// monitorTypeValidator returns a ValidateFunc that accepts
+// monitorTypes, warning about those in deprecated, which maps types
+// to advice on what to use instead.
+func monitorTypeValidator(deprecated map[string]string) schema.SchemaValidateFunc {
+	return func(i interface{}, k string) ([]string, []error) {
+		ws, es := validation.StringInSlice(monitorTypes, false)(i, k)
+		if advice, ok := deprecated[i.(string)]; ok {
+			ws = append(ws, fmt.Sprintf("%s: monitor type %s is deprecated by New Relic: %s", k, i, advice))
+		}
+		return ws, es
+	}
+}
+
+// monitorFrequencies are the checking frequencies, in minutes, that
+// New Relic allows.
+var monitorFrequencies = []int{1, 5, 10, 15, 30, 60, 360, 720, 1440}
+
+// monitorFrequencyNames describe each of monitorFrequencies in words.
+var monitorFrequencyNames = map[int]string{
+	1:    "every minute",
+	5:    "every 5 minutes",
+	10:   "every 10 minutes",
+	15:   "every 15 minutes",
+	30:   "every 30 minutes",
+	60:   "every hour",
+	360:  "every 6 hours",
+	720:  "every 12 hours",
+	1440: "every day",
+}
+
+// monitorTypeFrequencies are the frequencies each monitor type
+// allows, if it doesn't allow all of monitorFrequencies. Only simple
+// monitors can check every minute.
+var monitorTypeFrequencies = map[string][]int{
+	synthetics.TypeBrowser:       {5, 10, 15, 30, 60, 360, 720, 1440},
+	synthetics.TypeScriptAPI:     {5, 10, 15, 30, 60, 360, 720, 1440},
+	synthetics.TypeScriptBrowser: {5, 10, 15, 30, 60, 360, 720, 1440},
+}
+
+func validateFrequency(i interface{}, k string) ([]string, []error) {
+	frequency := i.(int)
+	if !containsInt(monitorFrequencies, frequency) {
+		return nil, []error{errors.Errorf("%s must be one of %v, got: %d", k, monitorFrequencies, frequency)}
+	}
+	return nil, nil
+}
+
+// roundSLAThreshold rounds an SLA threshold to the two decimal places
+// New Relic keeps, so configuration matches what the API returns.
+func roundSLAThreshold(threshold float64) float64 {
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(threshold, 'f', 2, 64), 64)
+	return rounded
+}
+
+// slaThresholdStateFunc stores a rounded SLA threshold, formatted the
+// way Terraform stores floats.
+func slaThresholdStateFunc(i interface{}) string {
+	return strconv.FormatFloat(roundSLAThreshold(i.(float64)), 'G', -1, 64)
+}
+
+// validateURI checks that a URI is an absolute http or https URL.
+func validateURI(i interface{}, k string) ([]string, []error) {
+	uri, err := url.Parse(i.(string))
+	if err != nil {
+		return nil, []error{errors.Wrapf(err, "%s must be a valid URL", k)}
+	}
+	if uri.Scheme != "http" && uri.Scheme != "https" || uri.Host == "" {
+		return nil, []error{errors.Errorf("%s must be an http or https URL, got: %s", k, i)}
+	}
+	return nil, nil
+}
+
 func sha256StateFunc(i interface{}) string {
-	s := i.(string)
+	s := normalizeScript(i.(string))
 	hash := sha256.New()
 	hash.Write([]byte(s))
 	return string(hash.Sum(nil))
 }
 
+// validateScript checks that a script is valid UTF-8 and within New
+// Relic's size limit.
+func validateScript(i interface{}, k string) ([]string, []error) {
+	if !utf8.ValidString(i.(string)) {
+		return nil, []error{errors.Errorf("%s must be valid UTF-8", k)}
+	}
+	if size := base64.StdEncoding.EncodedLen(len(normalizeScript(i.(string)))); size > maxScriptSize {
+		return nil, []error{errors.Errorf("%s is %d bytes once base64 encoded, more than New Relic's limit of %d", k, size, maxScriptSize)}
+	}
+	return nil, nil
+}
+
+// normalizeScript converts CRLF line endings in a script to LF, which
+// is what New Relic expects.
+func normalizeScript(script string) string {
+	return strings.Replace(script, "\r\n", "\n", -1)
+}
+
+// validateMonitor checks constraints between monitor attributes that
+// the schema can't express. It runs before any API calls so that an
+// invalid configuration doesn't leave a partially created monitor
+// behind. Every violated constraint is reported at once.
+func validateMonitor(resourceData *schema.ResourceData, defaultFrequency int) error {
+	var problems []string
+
+	monitorType := resourceData.Get("type").(string)
+	scripted := monitorType == synthetics.TypeScriptAPI || monitorType == synthetics.TypeScriptBrowser
+	_, hasScript := resourceData.GetOk("script")
+	scriptLocations := len(resourceData.Get("script_locations").([]interface{}))
+
+	if scripted && !hasScript && manageScript(resourceData) {
+		problems = append(problems, fmt.Sprintf("script is required for %s monitors", monitorType))
+	}
+	// Terraform 0.9 has no CustomizeDiff, so a uri set on a scripted
+	// monitor is only caught at apply time.
+	if _, hasURI := resourceData.GetOk("uri"); scripted && hasURI {
+		problems = append(problems, fmt.Sprintf("uri is not supported for %s monitors", monitorType))
+	}
+
+	frequency := monitorFrequency(resourceData, defaultFrequency)
+	if allowed, ok := monitorTypeFrequencies[monitorType]; ok && !containsInt(allowed, frequency) {
+		problems = append(problems, fmt.Sprintf("frequency must be one of %v for %s monitors, got: %d", allowed, monitorType, frequency))
+	}
+
+	// Duplicate public locations collapse into one element of the
+	// locations set, but script_locations is a list, so duplicate
+	// names are kept and reported here.
+	scriptLocationNames := make(map[string]bool)
+	for _, data := range resourceData.Get("script_locations").([]interface{}) {
+		scriptLocation := data.(map[string]interface{})
+		name := scriptLocation["name"].(string)
+		if scriptLocation["hmac"].(string) != "" && scriptLocation["hmac_file"].(string) != "" {
+			problems = append(problems, fmt.Sprintf("script location %s can't set both hmac and hmac_file", name))
+		}
+		if scriptLocationNames[name] {
+			problems = append(problems, fmt.Sprintf("script location %s is listed more than once", name))
+		}
+		scriptLocationNames[name] = true
+	}
+
+	problems = append(problems, validateMonitorLocations(
+		monitorType,
+		resourceData.Get("locations").(*schema.Set).Len(),
+		scriptLocations,
+	)...)
+
+	return monitorConfigError(problems)
+}
+
+// monitorFrequency returns the frequency a monitor checks at: its
+// configured frequency, or else the provider's default_frequency.
+func monitorFrequency(resourceData *schema.ResourceData, defaultFrequency int) int {
+	if data, ok := resourceData.GetOk("frequency"); ok {
+		return data.(int)
+	}
+	return defaultFrequency
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// privateLocationTypes records, for each monitor type, whether it
+// can run from private script locations as well as public ones. Only
+// script monitors can, from either kind or a mix of them.
+var privateLocationTypes = map[string]bool{
+	synthetics.TypeSimple:        false,
+	synthetics.TypeBrowser:       false,
+	synthetics.TypeScriptAPI:     true,
+	synthetics.TypeScriptBrowser: true,
+}
+
+// validateMonitorLocations checks that a monitor of the given type can
+// run from the given number of public locations and private script
+// locations. Every monitor needs somewhere to run from.
+func validateMonitorLocations(monitorType string, locations, scriptLocations int) []string {
+	private, ok := privateLocationTypes[monitorType]
+	if !ok {
+		return nil
+	}
+
+	// script_locations are ignored by monitors that can't use them.
+	if !private {
+		scriptLocations = 0
+	}
+
+	var problems []string
+	if locations == 0 && scriptLocations == 0 {
+		if private {
+			problems = append(problems, fmt.Sprintf("at least one location or script location is required for %s monitors", monitorType))
+		} else {
+			problems = append(problems, fmt.Sprintf("at least one location is required for %s monitors", monitorType))
+		}
+	}
+	return problems
+}
+
+// highVolumeLocations is the number of locations above which a
+// monitor checking every minute is considered high volume.
+const highVolumeLocations = 5
+
+// monitorWarnings returns advice about a monitor's configuration that
+// shouldn't block applying it. Terraform 0.9 can't surface warnings
+// that span attributes during plan, so these are logged.
+func monitorWarnings(resourceData *schema.ResourceData, defaultFrequency int) []string {
+	var warnings []string
+
+	frequency := monitorFrequency(resourceData, defaultFrequency)
+	locations := resourceData.Get("locations").(*schema.Set).Len()
+	if frequency == 1 && locations > highVolumeLocations {
+		warnings = append(warnings, fmt.Sprintf(
+			"checking every minute from %d locations runs %d checks a day, review the cost of this",
+			locations, locations*24*60,
+		))
+	}
+
+	return warnings
+}
+
+// monitorConfigError combines the problems found with a monitor's
+// configuration into a single error, or returns nil if there are none.
+func monitorConfigError(problems []string) error {
+	switch len(problems) {
+	case 0:
+		return nil
+	case 1:
+		return errors.Errorf("error: %s", problems[0])
+	}
+	return errors.Errorf("error: %d problems with monitor configuration:\n\n* %s", len(problems), strings.Join(problems, "\n* "))
+}
+
+// manageScript reports whether the provider manages a monitor's
+// script. States written before manage_script existed don't have it,
+// and keep having their scripts managed.
+func manageScript(resourceData *schema.ResourceData) bool {
+	// There is no state before a monitor is created.
+	state := resourceData.State()
+	if state == nil {
+		return resourceData.Get("manage_script").(bool)
+	}
+
+	managed, ok := state.Attributes["manage_script"]
+	return !ok || managed == "true"
+}
+
 // NRSMonitorCreate creates a new Synthetics monitor using Terraform
 // configuration.
 func NRSMonitorCreate(resourceData *schema.ResourceData, meta interface{}) error {
-	client := meta.(*synthetics.Client)
+	client := meta.(*providerMeta).client
+	defer meta.(*providerMeta).logRequestCounts("creating a monitor")
+
+	if err := validateMonitor(resourceData, meta.(*providerMeta).defaultFrequency); err != nil {
+		return err
+	}
+	for _, warning := range monitorWarnings(resourceData, meta.(*providerMeta).defaultFrequency) {
+		log.Printf("[WARN] monitor %s: %s", resourceData.Get("name"), warning)
+	}
+
+	if err := checkMonitorQuota(client, meta.(*providerMeta).monitorQuota); err != nil {
+		return err
+	}
+
+	frequency := monitorFrequency(resourceData, meta.(*providerMeta).defaultFrequency)
 
 	args := &synthetics.CreateMonitorArgs{
 		Name:         resourceData.Get("name").(string),
 		Type:         resourceData.Get("type").(string),
-		Frequency:    uint(resourceData.Get("frequency").(int)),
+		Frequency:    uint(frequency),
 		URI:          resourceData.Get("uri").(string),
 		Status:       resourceData.Get("status").(string),
-		SLAThreshold: resourceData.Get("sla_threshold").(float64),
+		SLAThreshold: roundSLAThreshold(resourceData.Get("sla_threshold").(float64)),
 	}
 
+	// Monitors that only run from private locations are created with
+	// an empty list of locations rather than null.
+	args.Locations = []string{}
 	if data, ok := resourceData.GetOk("locations"); ok {
 		locations := data.(*schema.Set)
-		args.Locations = util.StrSlice(locations.List())
+		args.Locations = normalizeLocations(locations.List())
 	}
-	if data, ok := resourceData.GetOk("validation_string"); ok {
+	if data, ok := getMonitorOption(resourceData, "validation_string"); ok {
 		args.ValidationString = util.StrPtr(data.(string))
 	}
-	if data, ok := resourceData.GetOk("verify_ssl"); ok {
-		args.VerifySSL = util.BoolPtr(data.(bool))
-	}
-	if data, ok := resourceData.GetOk("bypass_head_request"); ok {
-		args.BypassHEADRequest = util.BoolPtr(data.(bool))
-	}
-	if data, ok := resourceData.GetOk("treat_redirect_as_failure"); ok {
-		args.TreatRedirectAsFailure = util.BoolPtr(data.(bool))
-	}
+	defaults := defaultOptionsForType(args.Type)
+	args.VerifySSL = boolOption(resourceData, "verify_ssl", defaults)
+	args.BypassHEADRequest = boolOption(resourceData, "bypass_head_request", defaults)
+	args.TreatRedirectAsFailure = boolOption(resourceData, "treat_redirect_as_failure", defaults)
 
 	monitor, err := client.CreateMonitor(args)
 	if err != nil {
 		return errors.Wrapf(err, "error: could not create monitor")
 	}
+	// The ID is parsed from the Location header of New Relic's
+	// response, so make sure it's an ID before storing it.
+	if !monitorIDPattern.MatchString(monitor.ID) {
+		return errors.Errorf("error: New Relic returned a malformed monitor ID %q, the monitor may have been created anyway", monitor.ID)
+	}
 
 	resourceData.SetId(monitor.ID)
-	resourceData.Set("sla_threshold", monitor.SLAThreshold)
+	// The synthetics client reads the monitor back after creating it,
+	// so computed attributes can be set without another request and
+	// are available to the rest of the apply.
+	if err := setMonitorState(resourceData, monitor); err != nil {
+		return err
+	}
+	if err := resourceData.Set("monitor_url", meta.(*providerMeta).monitorURL(monitor.ID)); err != nil {
+		return err
+	}
 
 	// Set script if it was provided.
-	if data, ok := resourceData.GetOk("script"); ok {
+	scripted := args.Type == synthetics.TypeScriptAPI || args.Type == synthetics.TypeScriptBrowser
+	if !scripted {
+		if err := resourceData.Set("has_script", false); err != nil {
+			return err
+		}
+	}
+	data, hasScript := resourceData.GetOk("script")
+	if hasScript && manageScript(resourceData) {
+		locations, err := scriptLocations(resourceData)
+		if err != nil {
+			return err
+		}
 		args := &synthetics.UpdateMonitorScriptArgs{
-			ScriptText: data.(string),
-		}
-
-		// Set script locations
-		if data, ok := resourceData.GetOk("script_locations"); ok {
-			scriptLocations := data.([]map[string]interface{})
-			for _, scriptLocation := range scriptLocations {
-				args.ScriptLocations = append(
-					args.ScriptLocations,
-					&synthetics.ScriptLocation{
-						Name: scriptLocation["name"].(string),
-						HMAC: scriptLocation["hmac"].(string),
-					},
-				)
-			}
+			ScriptText:      normalizeScript(data.(string)),
+			ScriptLocations: locations,
 		}
 
 		if err := client.UpdateMonitorScript(monitor.ID, args); err != nil {
 			return errors.Wrap(err, "error: could not update monitor script")
 		}
+		if err := setHMACFileHashes(resourceData, locations); err != nil {
+			return err
+		}
 	}
+	if scripted && manageScript(resourceData) {
+		if err := resourceData.Set("has_script", hasScript); err != nil {
+			return err
+		}
+	}
+
+	return reconcileAlertPolicies(resourceData, client)
+}
 
+// defaultOptionsForType returns the options a monitor of the given
+// type is created with when they aren't configured, by attribute.
+// Sending them explicitly means New Relic stores the same values
+// Terraform assumes for unset attributes, so reading a new monitor
+// back shows no diff. Only the options a type supports have defaults.
+func defaultOptionsForType(monitorType string) map[string]bool {
+	switch monitorType {
+	case synthetics.TypeSimple:
+		return map[string]bool{
+			"verify_ssl":                false,
+			"bypass_head_request":       false,
+			"treat_redirect_as_failure": false,
+		}
+	case synthetics.TypeBrowser:
+		return map[string]bool{
+			"verify_ssl": false,
+		}
+	}
 	return nil
 }
 
-// NRSMonitorUpdate updates a Synthetics monitor using Terraform
-// configuration.
-func NRSMonitorUpdate(resourceData *schema.ResourceData, meta interface{}) error {
-	client := meta.(*synthetics.Client)
+// boolOption returns a boolean option to create a monitor with: its
+// configured value if it's set, or else its default, if it has one.
+func boolOption(resourceData *schema.ResourceData, attribute string, defaults map[string]bool) *bool {
+	if data, ok := getMonitorOption(resourceData, attribute); ok {
+		return util.BoolPtr(data.(bool))
+	}
+	if value, ok := defaults[attribute]; ok {
+		return util.BoolPtr(value)
+	}
+	return nil
+}
 
-	args := &synthetics.UpdateMonitorArgs{
-		Name:         resourceData.Get("name").(string),
-		Frequency:    uint(resourceData.Get("frequency").(int)),
-		URI:          resourceData.Get("uri").(string),
-		Status:       resourceData.Get("status").(string),
-		SLAThreshold: resourceData.Get("sla_threshold").(float64),
+// scriptLocations returns the configured private locations to run a
+// monitor's script from. HMACs configured with hmac_file are read
+// from their files.
+func scriptLocations(resourceData *schema.ResourceData) ([]*synthetics.ScriptLocation, error) {
+	var scriptLocations []*synthetics.ScriptLocation
+	for _, data := range resourceData.Get("script_locations").([]interface{}) {
+		scriptLocation := data.(map[string]interface{})
+
+		hmac := scriptLocation["hmac"].(string)
+		if path := scriptLocation["hmac_file"].(string); path != "" {
+			var err error
+			if hmac, err = readHMACFile(path); err != nil {
+				return nil, errors.Wrapf(err, "error: could not read HMAC for script location %s", scriptLocation["name"])
+			}
+		}
+
+		scriptLocations = append(scriptLocations, &synthetics.ScriptLocation{
+			Name: scriptLocation["name"].(string),
+			HMAC: hmac,
+		})
 	}
+	return scriptLocations, nil
+}
 
-	if resourceData.HasChange("locations") {
-		locations := resourceData.Get("locations").(*schema.Set)
-		args.Locations = util.StrSlice(locations.List())
+// readHMACFile reads a script location's HMAC from a file.
+func readHMACFile(path string) (string, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
 	}
-	if resourceData.HasChange("validation_string") {
-		validationString := resourceData.Get("validation_string").(string)
-		if validationString != "" {
-			args.ValidationString = util.StrPtr(validationString)
+	return strings.TrimSpace(string(contents)), nil
+}
+
+// hmacHash returns a hex SHA-256 hash of an HMAC.
+func hmacHash(hmac string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(hmac)))
+}
+
+// setHMACFileHashes stores hashes of the HMACs sent for script
+// locations that use hmac_file, given the locations returned by
+// scriptLocations, so that changes to the files can be detected.
+func setHMACFileHashes(resourceData *schema.ResourceData, locations []*synthetics.ScriptLocation) error {
+	scriptLocations := resourceData.Get("script_locations").([]interface{})
+	for i, data := range scriptLocations {
+		scriptLocation := data.(map[string]interface{})
+		scriptLocation["hmac_file_hash"] = ""
+		if scriptLocation["hmac_file"].(string) != "" {
+			scriptLocation["hmac_file_hash"] = hmacHash(locations[i].HMAC)
 		}
 	}
-	if resourceData.HasChange("verify_ssl") {
-		args.VerifySSL = util.BoolPtr(resourceData.Get("verify_ssl").(bool))
+	return resourceData.Set("script_locations", scriptLocations)
+}
+
+// detectHMACFileChanges clears hmac_file in state for script
+// locations whose file no longer holds the HMAC last sent. The next
+// plan then sets it again, which resends the script with the new
+// HMAC. Files that can't be read are left for apply to report.
+func detectHMACFileChanges(resourceData *schema.ResourceData) error {
+	scriptLocations := resourceData.Get("script_locations").([]interface{})
+	changed := false
+	for _, data := range scriptLocations {
+		scriptLocation := data.(map[string]interface{})
+		path, hash := scriptLocation["hmac_file"].(string), scriptLocation["hmac_file_hash"].(string)
+		if path == "" || hash == "" {
+			continue
+		}
+		if hmac, err := readHMACFile(path); err == nil && hmacHash(hmac) != hash {
+			log.Printf("[DEBUG] the HMAC in %s for script location %s has changed", path, scriptLocation["name"])
+			scriptLocation["hmac_file"] = ""
+			changed = true
+		}
 	}
-	if resourceData.HasChange("bypass_head_request") {
-		args.BypassHEADRequest = util.BoolPtr(resourceData.Get("bypass_head_request").(bool))
+	if !changed {
+		return nil
 	}
-	if resourceData.HasChange("treat_redirect_as_failure") {
-		args.TreatRedirectAsFailure = util.BoolPtr(resourceData.Get("treat_redirect_as_failure").(bool))
+	return resourceData.Set("script_locations", scriptLocations)
+}
+
+// checkMonitorQuota returns an error if the account already has quota
+// monitors. A quota of zero isn't checked.
+func checkMonitorQuota(client *synthetics.Client, quota uint) error {
+	if quota == 0 {
+		return nil
 	}
 
-	monitor, err := client.UpdateMonitor(resourceData.Id(), args)
+	response, err := client.GetAllMonitors(0, 1)
 	if err != nil {
-		return errors.Wrapf(err, "error: could not update monitor")
+		return errors.Wrap(err, "error: could not count monitors")
+	}
+	if response.Count >= quota {
+		return errors.Errorf("error: the account has %d monitors, which reaches the monitor_quota of %d", response.Count, quota)
 	}
-	if err := resourceData.Set("sla_threshold", monitor.SLAThreshold); err != nil {
+	return nil
+}
+
+// NRSMonitorUpdate updates a Synthetics monitor using Terraform
+// configuration.
+func NRSMonitorUpdate(resourceData *schema.ResourceData, meta interface{}) error {
+	client := meta.(*providerMeta).client
+	defer meta.(*providerMeta).logRequestCounts("updating a monitor")
+
+	if err := validateMonitor(resourceData, meta.(*providerMeta).defaultFrequency); err != nil {
 		return err
 	}
+	for _, warning := range monitorWarnings(resourceData, meta.(*providerMeta).defaultFrequency) {
+		log.Printf("[WARN] monitor %s: %s", resourceData.Get("name"), warning)
+	}
 
-	if resourceData.HasChange("script") {
-		script := resourceData.Get("script").(string)
-		scriptArgs := &synthetics.UpdateMonitorScriptArgs{
-			ScriptText: script,
+	meta.(*providerMeta).invalidateMonitor(resourceData.Id())
+
+	// UpdateMonitor resends status, so it's skipped when only the
+	// script changed to avoid clobbering out-of-band muting.
+	if hasMonitorChanges(resourceData) {
+		if err := updateMonitor(resourceData, client); err != nil {
+			return err
 		}
+	}
 
-		if data, ok := resourceData.GetOk("script_locations"); ok {
-			scriptLocations := data.([]map[string]interface{})
-			for _, scriptLocation := range scriptLocations {
-				scriptArgs.ScriptLocations = append(
-					scriptArgs.ScriptLocations,
-					&synthetics.ScriptLocation{
-						Name: scriptLocation["name"].(string),
-						HMAC: scriptLocation["hmac"].(string),
-					},
-				)
+	if (resourceData.HasChange("script") || resourceData.HasChange("script_locations")) && manageScript(resourceData) {
+		// State only has a hash of the script, so when just the script
+		// locations change, the script is resent as New Relic has it.
+		script := resourceData.Get("script").(string)
+		if !resourceData.HasChange("script") {
+			var err error
+			if script, err = meta.(*providerMeta).getMonitorScript(resourceData.Id()); err != nil {
+				return errors.Wrap(err, "error: could not get monitor script")
 			}
 		}
 
+		locations, err := scriptLocations(resourceData)
+		if err != nil {
+			return err
+		}
+		scriptArgs := &synthetics.UpdateMonitorScriptArgs{
+			ScriptText:      normalizeScript(script),
+			ScriptLocations: locations,
+		}
+
 		if err := client.UpdateMonitorScript(resourceData.Id(), scriptArgs); err != nil {
 			return errors.Wrapf(err, "error: could not update monitor script")
 		}
+		if err := setHMACFileHashes(resourceData, locations); err != nil {
+			return err
+		}
+	}
+
+	if resourceData.HasChange("alert_policy_ids") {
+		return reconcileAlertPolicies(resourceData, client)
 	}
 
 	return nil
 }
 
+// monitorUpdateAttributes are the attributes sent by UpdateMonitor.
+var monitorUpdateAttributes = []string{
+	"name",
+	"frequency",
+	"uri",
+	"locations",
+	"status",
+	"sla_threshold",
+	"options",
+	"validation_string",
+	"verify_ssl",
+	"bypass_head_request",
+	"treat_redirect_as_failure",
+}
+
+// hasMonitorChanges returns whether any attribute sent by
+// UpdateMonitor has changed.
+func hasMonitorChanges(resourceData *schema.ResourceData) bool {
+	for _, key := range monitorUpdateAttributes {
+		if resourceData.HasChange(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// updateMonitor sends a monitor's attributes, other than its script,
+// to New Relic and sets state from the updated monitor.
+func updateMonitor(resourceData *schema.ResourceData, client *synthetics.Client) error {
+	args := &synthetics.UpdateMonitorArgs{
+		Name:         resourceData.Get("name").(string),
+		Frequency:    uint(resourceData.Get("frequency").(int)),
+		URI:          resourceData.Get("uri").(string),
+		Status:       resourceData.Get("status").(string),
+		SLAThreshold: roundSLAThreshold(resourceData.Get("sla_threshold").(float64)),
+	}
+
+	// The synthetics client omits empty locations from updates, so a
+	// monitor's last public location can't be removed with an update.
+	if resourceData.HasChange("locations") {
+		locations := resourceData.Get("locations").(*schema.Set)
+		args.Locations = normalizeLocations(locations.List())
+	}
+	// An empty validation string is sent so that removing it from
+	// configuration clears it.
+	if monitorOptionChanged(resourceData, "validation_string") {
+		data, _ := getMonitorOption(resourceData, "validation_string")
+		args.ValidationString = util.StrPtr(data.(string))
+	}
+	if monitorOptionChanged(resourceData, "verify_ssl") {
+		data, _ := getMonitorOption(resourceData, "verify_ssl")
+		args.VerifySSL = util.BoolPtr(data.(bool))
+	}
+	if monitorOptionChanged(resourceData, "bypass_head_request") {
+		data, _ := getMonitorOption(resourceData, "bypass_head_request")
+		args.BypassHEADRequest = util.BoolPtr(data.(bool))
+	}
+	if monitorOptionChanged(resourceData, "treat_redirect_as_failure") {
+		data, _ := getMonitorOption(resourceData, "treat_redirect_as_failure")
+		args.TreatRedirectAsFailure = util.BoolPtr(data.(bool))
+	}
+
+	// UpdateMonitor returns the monitor as the API sees it after the
+	// update, so state can be set without another read.
+	monitor, err := client.UpdateMonitor(resourceData.Id(), args)
+	if err != nil {
+		return errors.Wrapf(err, "error: could not update monitor")
+	}
+	return setMonitorState(resourceData, monitor)
+}
+
 // NRSMonitorRead updates Terraform configuration for a Synthetics monitor.
 func NRSMonitorRead(resourceData *schema.ResourceData, meta interface{}) error {
-	client := meta.(*synthetics.Client)
-
-	monitor, err := client.GetMonitor(resourceData.Id())
+	monitor, err := meta.(*providerMeta).getMonitor(resourceData.Id())
 	if err != nil {
 		return errors.Wrap(err, "error: could not get monitor")
 	}
 
-	if monitor.Type == synthetics.TypeScriptAPI || monitor.Type == synthetics.TypeScriptBrowser {
-		script, err := client.GetMonitorScript(resourceData.Id())
+	if err := resourceData.Set("monitor_url", meta.(*providerMeta).monitorURL(monitor.ID)); err != nil {
+		return err
+	}
+
+	// Persist manage_script so that states written before it existed
+	// don't show a diff against its default.
+	managed := manageScript(resourceData)
+	if err := resourceData.Set("manage_script", managed); err != nil {
+		return err
+	}
+	// alert_condition_ids can't be read from New Relic, so it's kept as
+	// is, and alert_policy_ids follows from it. Setting it makes sure
+	// it's in state even when empty.
+	if err := setAlertConditions(resourceData, resourceData.Get("alert_condition_ids").(map[string]interface{})); err != nil {
+		return err
+	}
+
+	scripted := monitor.Type == synthetics.TypeScriptAPI || monitor.Type == synthetics.TypeScriptBrowser
+	if !scripted {
+		if err := resourceData.Set("has_script", false); err != nil {
+			return err
+		}
+	}
+	if scripted && managed {
+		script, err := meta.(*providerMeta).getMonitorScript(resourceData.Id())
+		if err := resourceData.Set("has_script", err == nil && script != ""); err != nil {
+			return err
+		}
 		switch err {
 		case synthetics.ErrMonitorScriptNotFound:
 			if err := resourceData.Set("script", nil); err != nil {
@@ -276,7 +915,15 @@ func NRSMonitorRead(resourceData *schema.ResourceData, meta interface{}) error {
 				return err
 			}
 		case nil:
-			if err := resourceData.Set("script", sha256StateFunc(script)); err != nil {
+			// An empty script is stored as-is rather than hashed, so
+			// it matches a configuration without a script.
+			if script != "" {
+				script = sha256StateFunc(script)
+			}
+			if err := resourceData.Set("script", script); err != nil {
+				return err
+			}
+			if err := detectHMACFileChanges(resourceData); err != nil {
 				return err
 			}
 		default:
@@ -284,89 +931,233 @@ func NRSMonitorRead(resourceData *schema.ResourceData, meta interface{}) error {
 		}
 	}
 
+	return setMonitorState(resourceData, monitor)
+}
+
+// setMonitorState sets the monitor attributes in Terraform state,
+// other than its script, from a monitor returned by the API.
+func setMonitorState(resourceData *schema.ResourceData, monitor *synthetics.Monitor) error {
 	if err := resourceData.Set("name", monitor.Name); err != nil {
 		return err
 	}
 	if err := resourceData.Set("type", monitor.Type); err != nil {
 		return err
 	}
-	if err := resourceData.Set("frequency", monitor.Frequency); err != nil {
+	// The API reports frequency as an unsigned integer, while the
+	// schema stores an int.
+	if err := resourceData.Set("frequency", int(monitor.Frequency)); err != nil {
+		return err
+	}
+	if err := resourceData.Set("frequency_human", monitorFrequencyNames[int(monitor.Frequency)]); err != nil {
 		return err
 	}
 	if err := resourceData.Set("uri", monitor.URI); err != nil {
 		return err
 	}
-	if err := resourceData.Set("locations", monitor.Locations); err != nil {
+	// Monitors that only run from private locations may have no
+	// locations, which the API reports as null.
+	locations := monitor.Locations
+	if locations == nil {
+		locations = []string{}
+	}
+	if err := resourceData.Set("locations", locations); err != nil {
 		return err
 	}
 	if err := resourceData.Set("status", monitor.Status); err != nil {
 		return err
 	}
-	if err := resourceData.Set("sla_threshold", monitor.SLAThreshold); err != nil {
+	if err := resourceData.Set("sla_threshold", roundSLAThreshold(monitor.SLAThreshold)); err != nil {
+		return err
+	}
+	if err := resourceData.Set("api_version", monitorAPIVersion(monitor)); err != nil {
 		return err
 	}
 
-	if monitor.ValidationString != nil {
-		if err := resourceData.Set("validation_string", *monitor.ValidationString); err != nil {
-			return err
-		}
-	} else {
-		if err := resourceData.Set("validation_string", nil); err != nil {
-			return err
-		}
+	optionsJSON, err := monitorOptionsJSON(monitor)
+	if err != nil {
+		return err
+	}
+	if err := resourceData.Set("options_json", optionsJSON); err != nil {
+		return err
 	}
 
-	if monitor.VerifySSL != nil {
-		if err := resourceData.Set("verify_ssl", *monitor.VerifySSL); err != nil {
-			return err
-		}
-	} else {
-		if err := resourceData.Set("verify_ssl", nil); err != nil {
-			return err
-		}
+	return setMonitorOptions(resourceData, monitor)
+}
+
+// monitorOptionsJSON serializes a monitor's options, including ones
+// the resource doesn't model. Keys are sorted so the result is stable.
+func monitorOptionsJSON(monitor *synthetics.Monitor) (string, error) {
+	options := monitor.Options
+	if options == nil {
+		options = map[string]interface{}{}
 	}
 
-	if monitor.BypassHEADRequest != nil {
-		if err := resourceData.Set("bypass_head_request", *monitor.BypassHEADRequest); err != nil {
-			return err
+	optionsJSON, err := json.Marshal(options)
+	if err != nil {
+		return "", errors.Wrap(err, "error: could not serialize monitor options")
+	}
+	return string(optionsJSON), nil
+}
+
+// setMonitorOptions sets the attributes backed by a monitor's options
+// in Terraform state, in the options block if it's used and at the top
+// level otherwise. Options the API doesn't report are cleared.
+func setMonitorOptions(resourceData *schema.ResourceData, monitor *synthetics.Monitor) error {
+	options := map[string]interface{}{
+		"validation_string":         optionalString(monitor.ValidationString),
+		"verify_ssl":                optionalBool(monitor.VerifySSL),
+		"bypass_head_request":       optionalBool(monitor.BypassHEADRequest),
+		"treat_redirect_as_failure": optionalBool(monitor.TreatRedirectAsFailure),
+	}
+
+	if usesOptionsBlock(resourceData) {
+		block := map[string]interface{}{
+			"validation_string":         "",
+			"verify_ssl":                false,
+			"bypass_head_request":       false,
+			"treat_redirect_as_failure": false,
 		}
-	} else {
-		if err := resourceData.Set("bypass_head_request", nil); err != nil {
-			return err
+		for attribute, value := range options {
+			if value != nil {
+				block[attribute] = value
+			}
+			if err := resourceData.Set(attribute, nil); err != nil {
+				return err
+			}
 		}
+		return resourceData.Set("options", []interface{}{block})
 	}
 
-	if monitor.TreatRedirectAsFailure != nil {
-		if err := resourceData.Set("treat_redirect_as_failure", *monitor.TreatRedirectAsFailure); err != nil {
-			return err
-		}
-	} else {
-		if err := resourceData.Set("treat_redirect_as_failure", nil); err != nil {
+	for attribute, value := range options {
+		if err := resourceData.Set(attribute, value); err != nil {
 			return err
 		}
 	}
+	return resourceData.Set("options", nil)
+}
 
-	return nil
+// usesOptionsBlock returns whether a monitor's options are set with
+// the options block rather than the deprecated top-level attributes.
+func usesOptionsBlock(resourceData *schema.ResourceData) bool {
+	return len(resourceData.Get("options").([]interface{})) > 0
+}
+
+// getMonitorOption returns the value of an option and whether it's
+// set, from the options block if it's used.
+func getMonitorOption(resourceData *schema.ResourceData, attribute string) (interface{}, bool) {
+	if usesOptionsBlock(resourceData) {
+		return resourceData.GetOk("options.0." + attribute)
+	}
+	return resourceData.GetOk(attribute)
+}
+
+// monitorOptionChanged returns whether an option has changed, in
+// either the options block or at the top level.
+func monitorOptionChanged(resourceData *schema.ResourceData, attribute string) bool {
+	return resourceData.HasChange(attribute) || resourceData.HasChange("options.0."+attribute)
+}
+
+// optionalString dereferences an optional string, returning nil,
+// which clears an attribute, if it isn't set.
+func optionalString(s *string) interface{} {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
+
+// optionalBool dereferences an optional bool, returning nil, which
+// clears an attribute, if it isn't set.
+func optionalBool(b *bool) interface{} {
+	if b == nil {
+		return nil
+	}
+	return *b
+}
+
+// monitorAPIVersion returns the API version of a monitor. Depending
+// on the monitor type, New Relic reports it either at the top level
+// or under the monitor's options.
+func monitorAPIVersion(monitor *synthetics.Monitor) string {
+	if monitor.APIVersion != "" {
+		return monitor.APIVersion
+	}
+	if apiVersion, ok := monitor.Options["apiVersion"].(string); ok {
+		return apiVersion
+	}
+	return ""
 }
 
 // NRSMonitorDelete deletes a Synthetics monitor using Terraform
 // configuration.
 func NRSMonitorDelete(resourceData *schema.ResourceData, meta interface{}) error {
-	client := meta.(*synthetics.Client)
+	client := meta.(*providerMeta).client
+	defer meta.(*providerMeta).logRequestCounts("deleting a monitor")
+
+	if err := detachAlertPolicies(resourceData, client); err != nil {
+		return err
+	}
 
+	meta.(*providerMeta).invalidateMonitor(resourceData.Id())
 	if err := client.DeleteMonitor(resourceData.Id()); err != nil {
 		return errors.Wrap(err, "error: could not delete monitor")
 	}
 
+	if resourceData.Get("wait_for_delete").(bool) {
+		return waitForMonitorDeletion(client, resourceData.Id(), resourceData.Timeout(schema.TimeoutDelete))
+	}
+
 	return nil
 }
 
+// deletePollInterval is how often waitForMonitorDeletion checks
+// whether a monitor is gone.
+var deletePollInterval = 5 * time.Second
+
+// waitForMonitorDeletion polls until a monitor is no longer found or
+// the timeout elapses.
+func waitForMonitorDeletion(client *synthetics.Client, id string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		_, err := client.GetMonitor(id)
+		if isNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "error: could not get monitor")
+		}
+
+		if time.Now().Add(deletePollInterval).After(deadline) {
+			return errors.Errorf("error: monitor %s still exists %s after deletion", id, timeout)
+		}
+		time.Sleep(deletePollInterval)
+	}
+}
+
+// NRSMonitorImport imports a Synthetics monitor by ID. Monitors of
+// types the resource doesn't support are refused rather than imported
+// into a state that can't be planned.
+func NRSMonitorImport(resourceData *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	monitor, err := meta.(*providerMeta).getMonitor(resourceData.Id())
+	if err != nil {
+		return nil, errors.Wrapf(err, "error: could not get monitor %s", resourceData.Id())
+	}
+
+	for _, monitorType := range monitorTypes {
+		if monitor.Type == monitorType {
+			return []*schema.ResourceData{resourceData}, nil
+		}
+	}
+	return nil, errors.Errorf(
+		"error: monitor %s has type %s, which isn't supported. Supported types are %s",
+		resourceData.Id(), monitor.Type, strings.Join(monitorTypes, ", "),
+	)
+}
+
 // NRSMonitorExists checks whether a Synthetics monitor exists.
 func NRSMonitorExists(resourceData *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(*synthetics.Client)
-
-	if _, err := client.GetMonitor(resourceData.Id()); err != nil {
-		if err == synthetics.ErrMonitorNotFound {
+	if _, err := meta.(*providerMeta).getMonitor(resourceData.Id()); err != nil {
+		if isNotFound(err) {
 			return false, nil
 		}
 		return false, errors.Wrap(err, "error: could not get monitor")
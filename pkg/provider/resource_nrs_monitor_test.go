@@ -0,0 +1,2072 @@
+package provider
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	synthetics "github.com/dollarshaveclub/new-relic-synthetics-go"
+	"github.com/dollarshaveclub/new-relic-synthetics-go/util"
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// readMonitor refreshes a monitor with the given ID against the fake
+// API and returns the resulting state.
+func readMonitor(t *testing.T, fake *fakeSynthetics, id string) *terraform.InstanceState {
+	resourceData := NRSMonitorResource().Data(&terraform.InstanceState{ID: id})
+	if err := NRSMonitorRead(resourceData, fake.Meta(t)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return resourceData.State()
+}
+
+func TestNRSMonitorReadAPIVersionFromOptions(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.Handle("GET", monitorPath(testMonitorID), jsonResponse(http.StatusOK, `{
+		"id": "`+testMonitorID+`",
+		"name": "api-monitor",
+		"type": "SCRIPT_API",
+		"frequency": 10,
+		"locations": ["AWS_US_WEST_1"],
+		"status": "ENABLED",
+		"slaThreshold": 7,
+		"options": {"apiVersion": "0.5.0"}
+	}`))
+
+	state := readMonitor(t, fake, testMonitorID)
+	if got := state.Attributes["api_version"]; got != "0.5.0" {
+		t.Fatalf("expected api_version 0.5.0, got %q", got)
+	}
+
+	diff := planResource(t, NRSMonitorResource(), state, map[string]interface{}{
+		"name":          "api-monitor",
+		"type":          "SCRIPT_API",
+		"frequency":     10,
+		"locations":     []interface{}{"AWS_US_WEST_1"},
+		"status":        "ENABLED",
+		"sla_threshold": 7,
+	})
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan, got: %#v", diff.Attributes)
+	}
+}
+
+func TestNRSMonitorReadTypeDriftForcesNew(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.Handle("GET", monitorPath(testMonitorID), jsonResponse(http.StatusOK, `{
+		"id": "`+testMonitorID+`",
+		"name": "monitor",
+		"type": "BROWSER",
+		"frequency": 10,
+		"uri": "https://example.com",
+		"locations": ["AWS_US_WEST_1"],
+		"status": "ENABLED",
+		"slaThreshold": 7
+	}`))
+
+	state := readMonitor(t, fake, testMonitorID)
+	if got := state.Attributes["type"]; got != "BROWSER" {
+		t.Fatalf("expected type BROWSER from the API, got %q", got)
+	}
+
+	diff := planResource(t, NRSMonitorResource(), state, map[string]interface{}{
+		"name":          "monitor",
+		"type":          "SIMPLE",
+		"frequency":     10,
+		"uri":           "https://example.com",
+		"locations":     []interface{}{"AWS_US_WEST_1"},
+		"status":        "ENABLED",
+		"sla_threshold": 7,
+	})
+	if !diff.RequiresNew() {
+		t.Fatalf("expected a planned replacement, got: %#v", diff)
+	}
+	if attr := diff.Attributes["type"]; attr == nil || attr.Old != "BROWSER" || attr.New != "SIMPLE" {
+		t.Fatalf("expected type to change from BROWSER to SIMPLE, got: %#v", attr)
+	}
+}
+
+func TestValidateScript(t *testing.T) {
+	if _, errs := validateScript("console.log('héllo');\n", "script"); len(errs) != 0 {
+		t.Fatalf("expected a valid UTF-8 script to pass, got: %v", errs)
+	}
+	if _, errs := validateScript("console.log('\xff');\n", "script"); len(errs) == 0 {
+		t.Fatal("expected an invalid UTF-8 script to fail")
+	}
+	if _, errs := validateScript(strings.Repeat("a", maxScriptSize/4*3), "script"); len(errs) != 0 {
+		t.Fatalf("expected a script at the size limit to pass, got: %v", errs)
+	}
+	if _, errs := validateScript(strings.Repeat("a", maxScriptSize/4*3+1), "script"); len(errs) == 0 {
+		t.Fatal("expected a script over the size limit to fail")
+	}
+}
+
+func TestValidateURI(t *testing.T) {
+	for _, test := range []struct {
+		uri   string
+		valid bool
+	}{
+		{"https://example.com/health", true},
+		{"http://example.com", true},
+		{"example.com", false},
+		{"ftp://example.com", false},
+	} {
+		_, errs := validateURI(test.uri, "uri")
+		if valid := len(errs) == 0; valid != test.valid {
+			t.Fatalf("expected %s valid to be %t, got errors: %v", test.uri, test.valid, errs)
+		}
+	}
+}
+
+func TestNRSMonitorCreateNormalizesScriptLineEndings(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.handleCreateMonitor(testMonitorID, `{"id": "`+testMonitorID+`", "type": "SCRIPT_API", "slaThreshold": 7}`)
+
+	resourceData := schema.TestResourceDataRaw(t, NRSMonitorResource().Schema, map[string]interface{}{
+		"name":      "script-monitor",
+		"type":      "SCRIPT_API",
+		"frequency": 10,
+		"locations": []interface{}{"AWS_US_WEST_1"},
+		"status":    "ENABLED",
+		"script":    "var a = 1;\r\nvar b = 2;\r\n",
+	})
+	if err := NRSMonitorCreate(resourceData, fake.Meta(t)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	requests := fake.Requests("PUT", monitorPath(testMonitorID)+"/script")
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 script update, got %d", len(requests))
+	}
+	var body struct {
+		ScriptText string `json:"scriptText"`
+	}
+	if err := json.Unmarshal([]byte(requests[0].Body), &body); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	script, err := base64.StdEncoding.DecodeString(body.ScriptText)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(script) != "var a = 1;\nvar b = 2;\n" {
+		t.Fatalf("expected LF line endings, got %q", script)
+	}
+
+	if sha256StateFunc("a\r\nb") != sha256StateFunc("a\nb") {
+		t.Fatal("expected CRLF and LF scripts to hash identically")
+	}
+}
+
+func TestNRSMonitorNameDriftIsReconciled(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.Handle("GET", monitorPath(testMonitorID), jsonResponse(http.StatusOK, `{
+		"id": "`+testMonitorID+`",
+		"name": "renamed-out-of-band",
+		"type": "SIMPLE",
+		"frequency": 10,
+		"uri": "https://example.com",
+		"locations": ["AWS_US_WEST_1"],
+		"status": "ENABLED",
+		"slaThreshold": 7
+	}`))
+	fake.Handle("PATCH", monitorPath(testMonitorID), jsonResponse(http.StatusNoContent, ""))
+
+	state := readMonitor(t, fake, testMonitorID)
+	diff := planResource(t, NRSMonitorResource(), state, map[string]interface{}{
+		"name":          "configured-name",
+		"type":          "SIMPLE",
+		"frequency":     10,
+		"uri":           "https://example.com",
+		"locations":     []interface{}{"AWS_US_WEST_1"},
+		"status":        "ENABLED",
+		"sla_threshold": 7,
+	})
+	if diff.RequiresNew() {
+		t.Fatal("expected an in-place update, got a replacement")
+	}
+	if attr := diff.Attributes["name"]; attr == nil || attr.Old != "renamed-out-of-band" || attr.New != "configured-name" {
+		t.Fatalf("expected name to change back to configured-name, got: %#v", attr)
+	}
+
+	if _, err := NRSMonitorResource().Apply(state, diff, fake.Meta(t)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	requests := fake.Requests("PATCH", monitorPath(testMonitorID))
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 monitor update, got %d", len(requests))
+	}
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal([]byte(requests[0].Body), &body); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if body.Name != "configured-name" {
+		t.Fatalf("expected the update to restore configured-name, got %q", body.Name)
+	}
+}
+
+func TestNRSMonitorReadNullLocations(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.Handle("GET", monitorPath(testMonitorID), jsonResponse(http.StatusOK, `{
+		"id": "`+testMonitorID+`",
+		"name": "private-monitor",
+		"type": "SCRIPT_API",
+		"frequency": 10,
+		"locations": null,
+		"status": "ENABLED",
+		"slaThreshold": 7
+	}`))
+
+	state := readMonitor(t, fake, testMonitorID)
+	if got := state.Attributes["locations.#"]; got != "0" {
+		t.Fatalf("expected an empty locations set, got %q", got)
+	}
+}
+
+func TestNRSMonitorCreateRequiresScriptForScriptTypes(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.handleCreateMonitor(testMonitorID, `{"id": "`+testMonitorID+`", "type": "SCRIPT_API", "slaThreshold": 7}`)
+
+	raw := map[string]interface{}{
+		"name":      "script-monitor",
+		"type":      "SCRIPT_API",
+		"frequency": 10,
+		"locations": []interface{}{"AWS_US_WEST_1"},
+		"status":    "ENABLED",
+	}
+	resourceData := schema.TestResourceDataRaw(t, NRSMonitorResource().Schema, raw)
+	if err := NRSMonitorCreate(resourceData, fake.Meta(t)); err == nil {
+		t.Fatal("expected an error for a SCRIPT_API monitor without a script")
+	}
+	if got := len(fake.Requests("POST", "/synthetics/api/v3/monitors")); got != 0 {
+		t.Fatalf("expected no monitor to be created, got %d requests", got)
+	}
+
+	raw["script"] = "console.log('check');"
+	resourceData = schema.TestResourceDataRaw(t, NRSMonitorResource().Schema, raw)
+	if err := NRSMonitorCreate(resourceData, fake.Meta(t)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestValidateMonitorLocations(t *testing.T) {
+	cases := []struct {
+		monitorType     string
+		locations       []interface{}
+		scriptLocations []interface{}
+		valid           bool
+	}{
+		{"SIMPLE", nil, nil, false},
+		{"SIMPLE", []interface{}{"AWS_US_WEST_1"}, nil, true},
+		{"BROWSER", nil, nil, false},
+		{"SCRIPT_API", nil, nil, false},
+		{"SCRIPT_API", nil, []interface{}{map[string]interface{}{"name": "private", "hmac": "hmac"}}, true},
+	}
+
+	for _, c := range cases {
+		raw := map[string]interface{}{
+			"name":      "monitor",
+			"type":      c.monitorType,
+			"frequency": 10,
+			"status":    "ENABLED",
+		}
+		if strings.HasPrefix(c.monitorType, "SCRIPT_") {
+			raw["script"] = "console.log('check');"
+		}
+		if c.locations != nil {
+			raw["locations"] = c.locations
+		}
+		if c.scriptLocations != nil {
+			raw["script_locations"] = c.scriptLocations
+		}
+
+		err := validateMonitor(schema.TestResourceDataRaw(t, NRSMonitorResource().Schema, raw), 10)
+		if c.valid && err != nil {
+			t.Fatalf("expected %s with locations %v and script locations %v to be valid, got: %s", c.monitorType, c.locations, c.scriptLocations, err)
+		}
+		if !c.valid && err == nil {
+			t.Fatalf("expected %s with locations %v and script locations %v to be invalid", c.monitorType, c.locations, c.scriptLocations)
+		}
+	}
+}
+
+func TestValidateMonitorLocationCombinations(t *testing.T) {
+	cases := []struct {
+		monitorType     string
+		locations       int
+		scriptLocations int
+		problem         string
+	}{
+		{"SIMPLE", 0, 0, "at least one location is required"},
+		{"SIMPLE", 1, 0, ""},
+		{"SIMPLE", 0, 1, "at least one location is required"},
+		{"SIMPLE", 1, 1, ""},
+		{"BROWSER", 0, 0, "at least one location is required"},
+		{"BROWSER", 2, 0, ""},
+		{"BROWSER", 0, 1, "at least one location is required"},
+		{"BROWSER", 1, 1, ""},
+		{"SCRIPT_API", 0, 0, "at least one location or script location is required"},
+		{"SCRIPT_API", 1, 0, ""},
+		{"SCRIPT_API", 0, 1, ""},
+		{"SCRIPT_API", 1, 1, ""},
+		{"SCRIPT_BROWSER", 0, 0, "at least one location or script location is required"},
+		{"SCRIPT_BROWSER", 1, 0, ""},
+		{"SCRIPT_BROWSER", 0, 2, ""},
+		{"SCRIPT_BROWSER", 1, 1, ""},
+	}
+
+	for _, c := range cases {
+		problems := validateMonitorLocations(c.monitorType, c.locations, c.scriptLocations)
+		if c.problem == "" {
+			if len(problems) != 0 {
+				t.Fatalf("expected %s with %d locations and %d script locations to be valid, got: %v", c.monitorType, c.locations, c.scriptLocations, problems)
+			}
+			continue
+		}
+		if !strings.Contains(strings.Join(problems, "\n"), c.problem) {
+			t.Fatalf("expected %s with %d locations and %d script locations to fail with %q, got: %v", c.monitorType, c.locations, c.scriptLocations, c.problem, problems)
+		}
+	}
+}
+
+func TestValidateMonitorFrequencyForType(t *testing.T) {
+	cases := []struct {
+		monitorType string
+		frequency   int
+		valid       bool
+	}{
+		{"SIMPLE", 1, true},
+		{"SCRIPT_BROWSER", 1, false},
+		{"SCRIPT_BROWSER", 5, true},
+	}
+
+	for _, c := range cases {
+		raw := map[string]interface{}{
+			"name":      "monitor",
+			"type":      c.monitorType,
+			"frequency": c.frequency,
+			"locations": []interface{}{"AWS_US_WEST_1"},
+			"status":    "ENABLED",
+		}
+		if strings.HasPrefix(c.monitorType, "SCRIPT_") {
+			raw["script"] = "console.log('check');"
+		}
+
+		err := validateMonitor(schema.TestResourceDataRaw(t, NRSMonitorResource().Schema, raw), 10)
+		if c.valid && err != nil {
+			t.Fatalf("expected %s at %d minutes to be valid, got: %s", c.monitorType, c.frequency, err)
+		}
+		if !c.valid && (err == nil || !strings.Contains(err.Error(), "frequency")) {
+			t.Fatalf("expected %s at %d minutes to be invalid, got: %v", c.monitorType, c.frequency, err)
+		}
+	}
+}
+
+func TestMonitorWarningsHighVolume(t *testing.T) {
+	manyLocations := []interface{}{"AWS_US_WEST_1", "AWS_US_WEST_2", "AWS_US_EAST_1", "AWS_US_EAST_2", "AWS_EU_WEST_1", "AWS_EU_WEST_2"}
+	// A frequency of 0 leaves it unset, so the default applies.
+	cases := []struct {
+		frequency        int
+		defaultFrequency int
+		locations        []interface{}
+		warned           bool
+	}{
+		{1, 10, manyLocations, true},
+		{1, 10, manyLocations[:highVolumeLocations], false},
+		{5, 10, manyLocations, false},
+		{0, 1, manyLocations, true},
+		{0, 10, manyLocations, false},
+	}
+
+	for _, c := range cases {
+		raw := map[string]interface{}{
+			"name":      "monitor",
+			"type":      "SIMPLE",
+			"locations": c.locations,
+			"status":    "ENABLED",
+		}
+		if c.frequency != 0 {
+			raw["frequency"] = c.frequency
+		}
+		resourceData := schema.TestResourceDataRaw(t, NRSMonitorResource().Schema, raw)
+		warnings := monitorWarnings(resourceData, c.defaultFrequency)
+		if c.warned && (len(warnings) != 1 || !strings.Contains(warnings[0], "cost")) {
+			t.Fatalf("expected a cost warning at %d minutes from %d locations, got: %v", c.frequency, len(c.locations), warnings)
+		}
+		if !c.warned && len(warnings) != 0 {
+			t.Fatalf("expected no warnings at %d minutes from %d locations, got: %v", c.frequency, len(c.locations), warnings)
+		}
+	}
+}
+
+func TestNRSMonitorCreateChecksDefaultFrequencyForType(t *testing.T) {
+	fake := newFakeSynthetics()
+	meta := fake.Meta(t)
+	meta.defaultFrequency = 1
+
+	resourceData := schema.TestResourceDataRaw(t, NRSMonitorResource().Schema, map[string]interface{}{
+		"name":      "monitor",
+		"type":      "SCRIPT_BROWSER",
+		"locations": []interface{}{"AWS_US_WEST_1"},
+		"status":    "ENABLED",
+		"script":    "console.log('check');",
+	})
+	err := NRSMonitorCreate(resourceData, meta)
+	if err == nil || !strings.Contains(err.Error(), "frequency must be one of") {
+		t.Fatalf("expected the default frequency to be rejected for SCRIPT_BROWSER, got: %v", err)
+	}
+	if requests := fake.Requests("POST", "/synthetics/api/v3/monitors"); len(requests) != 0 {
+		t.Fatalf("expected no monitor to be created, got %d requests", len(requests))
+	}
+}
+
+func TestValidateMonitorReportsAllProblems(t *testing.T) {
+	resourceData := schema.TestResourceDataRaw(t, NRSMonitorResource().Schema, map[string]interface{}{
+		"name":      "monitor",
+		"type":      "SCRIPT_API",
+		"frequency": 10,
+		"status":    "ENABLED",
+		"uri":       "https://example.com",
+	})
+
+	err := validateMonitor(resourceData, 10)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, problem := range []string{
+		"script is required",
+		"uri is not supported",
+		"at least one location or script location is required",
+	} {
+		if !strings.Contains(err.Error(), problem) {
+			t.Fatalf("expected the error to include %q, got: %s", problem, err)
+		}
+	}
+}
+
+func TestNRSMonitorCreateValidatesID(t *testing.T) {
+	for id, valid := range map[string]bool{
+		testMonitorID:             true,
+		"2ff7fea8-2a1c-4d39-9ea7": false,
+		"not-an-id":               false,
+	} {
+		fake := newFakeSynthetics()
+		fake.handleCreateMonitor(id, `{"id": "`+id+`", "type": "SIMPLE", "slaThreshold": 7}`)
+
+		resourceData := schema.TestResourceDataRaw(t, NRSMonitorResource().Schema, map[string]interface{}{
+			"name":      "monitor",
+			"type":      "SIMPLE",
+			"frequency": 10,
+			"uri":       "https://example.com",
+			"locations": []interface{}{"AWS_US_WEST_1"},
+			"status":    "ENABLED",
+		})
+		err := NRSMonitorCreate(resourceData, fake.Meta(t))
+		if valid && err != nil {
+			t.Fatalf("expected monitor ID %s to be accepted, got: %s", id, err)
+		}
+		if !valid && (err == nil || !strings.Contains(err.Error(), "malformed monitor ID")) {
+			t.Fatalf("expected monitor ID %s to be rejected, got: %v", id, err)
+		}
+		if !valid && resourceData.Id() != "" {
+			t.Fatalf("expected no ID to be stored for %s, got %s", id, resourceData.Id())
+		}
+	}
+}
+
+func TestNRSMonitorCreateMonitorQuota(t *testing.T) {
+	for quota, allowed := range map[uint]bool{0: true, 10: false, 11: true} {
+		fake := newFakeSynthetics()
+		fake.handleCreateMonitor(testMonitorID, `{"id": "`+testMonitorID+`", "type": "SIMPLE", "slaThreshold": 7}`)
+		fake.Handle("GET", "/synthetics/api/v3/monitors", jsonResponse(http.StatusOK, `{"count": 10, "monitors": []}`))
+
+		meta := fake.Meta(t)
+		meta.monitorQuota = quota
+		resourceData := schema.TestResourceDataRaw(t, NRSMonitorResource().Schema, map[string]interface{}{
+			"name":      "monitor",
+			"type":      "SIMPLE",
+			"frequency": 10,
+			"uri":       "https://example.com",
+			"locations": []interface{}{"AWS_US_WEST_1"},
+			"status":    "ENABLED",
+		})
+		err := NRSMonitorCreate(resourceData, meta)
+
+		created := len(fake.Requests("POST", "/synthetics/api/v3/monitors")) == 1
+		if allowed && (err != nil || !created) {
+			t.Fatalf("expected a quota of %d to allow creation, got: %v", quota, err)
+		}
+		if !allowed && (err == nil || !strings.Contains(err.Error(), "monitor_quota") || created) {
+			t.Fatalf("expected a quota of %d to block creation, got: %v", quota, err)
+		}
+	}
+}
+
+func TestNRSMonitorCreateDefaultFrequency(t *testing.T) {
+	for _, frequency := range []int{0, 60} {
+		fake := newFakeSynthetics()
+		fake.handleCreateMonitor(testMonitorID, `{"id": "`+testMonitorID+`", "type": "SIMPLE", "slaThreshold": 7}`)
+
+		raw := map[string]interface{}{
+			"name":      "monitor",
+			"type":      "SIMPLE",
+			"uri":       "https://example.com",
+			"locations": []interface{}{"AWS_US_WEST_1"},
+			"status":    "ENABLED",
+		}
+		want := 15
+		if frequency != 0 {
+			raw["frequency"] = frequency
+			want = frequency
+		}
+
+		meta := fake.Meta(t)
+		meta.defaultFrequency = 15
+		resourceData := schema.TestResourceDataRaw(t, NRSMonitorResource().Schema, raw)
+		if err := NRSMonitorCreate(resourceData, meta); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		var body struct {
+			Frequency int `json:"frequency"`
+		}
+		if err := json.Unmarshal([]byte(fake.Requests("POST", "/synthetics/api/v3/monitors")[0].Body), &body); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if body.Frequency != want {
+			t.Fatalf("expected frequency %d to be sent, got %d", want, body.Frequency)
+		}
+	}
+}
+
+func TestSetMonitorOptions(t *testing.T) {
+	attributes := []string{"validation_string", "verify_ssl", "bypass_head_request", "treat_redirect_as_failure"}
+
+	// Each bit of present says whether the corresponding attribute's
+	// option is reported by the API.
+	for present := 0; present < 1<<uint(len(attributes)); present++ {
+		monitor := &synthetics.Monitor{}
+		if present&1 != 0 {
+			monitor.ValidationString = util.StrPtr("OK")
+		}
+		if present&2 != 0 {
+			monitor.VerifySSL = util.BoolPtr(true)
+		}
+		if present&4 != 0 {
+			monitor.BypassHEADRequest = util.BoolPtr(true)
+		}
+		if present&8 != 0 {
+			monitor.TreatRedirectAsFailure = util.BoolPtr(true)
+		}
+
+		// The prior state has every option set, so absent options
+		// must be cleared.
+		resourceData := NRSMonitorResource().Data(&terraform.InstanceState{
+			ID: testMonitorID,
+			Attributes: map[string]string{
+				"validation_string":         "stale",
+				"verify_ssl":                "true",
+				"bypass_head_request":       "true",
+				"treat_redirect_as_failure": "true",
+			},
+		})
+		if err := setMonitorOptions(resourceData, monitor); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		// Terraform stores a cleared bool as false.
+		state := resourceData.State()
+		presentValues := []string{"OK", "true", "true", "true"}
+		absentValues := []string{"", "false", "false", "false"}
+		for i, attribute := range attributes {
+			want := absentValues[i]
+			if present&(1<<uint(i)) != 0 {
+				want = presentValues[i]
+			}
+			if got := state.Attributes[attribute]; got != want {
+				t.Fatalf("options %04b: expected %s to be %q, got %q", present, attribute, want, got)
+			}
+		}
+	}
+}
+
+func TestNRSMonitorOptionsJSON(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.Handle("GET", monitorPath(testMonitorID), jsonResponse(http.StatusOK, `{
+		"id": "`+testMonitorID+`",
+		"name": "monitor",
+		"type": "SIMPLE",
+		"frequency": 10,
+		"uri": "https://example.com",
+		"locations": ["AWS_US_WEST_1"],
+		"status": "ENABLED",
+		"options": {"verifySSL": true, "unmodeledOption": [1, 2], "bypassHEADRequest": false}
+	}`))
+
+	expected := `{"bypassHEADRequest":false,"unmodeledOption":[1,2],"verifySSL":true}`
+	for i := 0; i < 3; i++ {
+		state := readMonitor(t, fake, testMonitorID)
+		if got := state.Attributes["options_json"]; got != expected {
+			t.Fatalf("expected options_json %s, got %s", expected, got)
+		}
+	}
+
+	fake.Handle("GET", monitorPath(testMonitorID), jsonResponse(http.StatusOK, `{"id": "`+testMonitorID+`", "type": "SIMPLE"}`))
+	if got := readMonitor(t, fake, testMonitorID).Attributes["options_json"]; got != "{}" {
+		t.Fatalf("expected empty options, got %s", got)
+	}
+}
+
+func TestNRSMonitorFrequencyRoundTrip(t *testing.T) {
+	for _, frequency := range monitorFrequencies {
+		fake := newFakeSynthetics()
+		fake.Handle("GET", monitorPath(testMonitorID), jsonResponse(http.StatusOK, fmt.Sprintf(`{
+			"id": "`+testMonitorID+`",
+			"name": "monitor",
+			"type": "SIMPLE",
+			"frequency": %d,
+			"uri": "https://example.com",
+			"locations": ["AWS_US_WEST_1"],
+			"status": "ENABLED"
+		}`, frequency)))
+
+		state := readMonitor(t, fake, testMonitorID)
+		if got := state.Attributes["frequency"]; got != strconv.Itoa(frequency) {
+			t.Fatalf("expected frequency %d in state, got %q", frequency, got)
+		}
+
+		diff := planResource(t, NRSMonitorResource(), state, map[string]interface{}{
+			"name":      "monitor",
+			"type":      "SIMPLE",
+			"frequency": frequency,
+			"uri":       "https://example.com",
+			"locations": []interface{}{"AWS_US_WEST_1"},
+			"status":    "ENABLED",
+		})
+		if !diff.Empty() {
+			t.Fatalf("expected frequency %d to round-trip cleanly, got: %#v", frequency, diff.Attributes)
+		}
+	}
+}
+
+func TestNRSMonitorLocationsOrderInsensitive(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.Handle("GET", monitorPath(testMonitorID), jsonResponse(http.StatusOK, `{
+		"id": "`+testMonitorID+`",
+		"name": "monitor",
+		"type": "SIMPLE",
+		"frequency": 10,
+		"uri": "https://example.com",
+		"locations": ["AWS_US_WEST_1", "AWS_US_EAST_1"],
+		"status": "ENABLED"
+	}`))
+
+	state := readMonitor(t, fake, testMonitorID)
+	diff := planResource(t, NRSMonitorResource(), state, map[string]interface{}{
+		"name":      "monitor",
+		"type":      "SIMPLE",
+		"frequency": 10,
+		"uri":       "https://example.com",
+		"locations": []interface{}{"AWS_US_EAST_1", "AWS_US_WEST_1"},
+		"status":    "ENABLED",
+	})
+	if !diff.Empty() {
+		t.Fatalf("expected reordered locations to produce an empty plan, got: %#v", diff.Attributes)
+	}
+}
+
+func TestNRSMonitorUpdateSetsStateFromUpdatedMonitor(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.Handle("GET", monitorPath(testMonitorID), jsonResponse(http.StatusOK, `{
+		"id": "`+testMonitorID+`",
+		"name": "monitor",
+		"type": "SIMPLE",
+		"frequency": 10,
+		"uri": "https://example.com",
+		"locations": ["AWS_US_WEST_1"],
+		"status": "ENABLED",
+		"slaThreshold": 7
+	}`))
+	state := readMonitor(t, fake, testMonitorID)
+
+	fake.Handle("PATCH", monitorPath(testMonitorID), jsonResponse(http.StatusNoContent, ""))
+	fake.Handle("GET", monitorPath(testMonitorID), jsonResponse(http.StatusOK, `{
+		"id": "`+testMonitorID+`",
+		"name": "monitor",
+		"type": "SIMPLE",
+		"frequency": 15,
+		"uri": "https://example.com",
+		"locations": ["AWS_US_WEST_1"],
+		"status": "ENABLED",
+		"slaThreshold": 7,
+		"apiVersion": "LATEST"
+	}`))
+	diff := planResource(t, NRSMonitorResource(), state, map[string]interface{}{
+		"name":          "monitor",
+		"type":          "SIMPLE",
+		"frequency":     15,
+		"uri":           "https://example.com",
+		"locations":     []interface{}{"AWS_US_WEST_1"},
+		"status":        "ENABLED",
+		"sla_threshold": 7,
+	})
+
+	gets := len(fake.Requests("GET", monitorPath(testMonitorID)))
+	state, err := NRSMonitorResource().Apply(state, diff, fake.Meta(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := len(fake.Requests("GET", monitorPath(testMonitorID))) - gets; got != 1 {
+		t.Fatalf("expected only UpdateMonitor's own GET, got %d", got)
+	}
+	if got := state.Attributes["frequency"]; got != "15" {
+		t.Fatalf("expected frequency 15, got %q", got)
+	}
+	if got := state.Attributes["api_version"]; got != "LATEST" {
+		t.Fatalf("expected state to be set from the updated monitor, got api_version %q", got)
+	}
+}
+
+func TestValidateMonitorTypeDeprecation(t *testing.T) {
+	validateMonitorType := monitorTypeValidator(map[string]string{"BROWSER": "use SCRIPT_BROWSER instead"})
+	resource := NRSMonitorResource()
+	resource.Schema["type"].ValidateFunc = validateMonitorType
+
+	c, err := config.NewRawConfig(map[string]interface{}{
+		"name":      "monitor",
+		"type":      "BROWSER",
+		"frequency": 10,
+		"locations": []interface{}{"AWS_US_WEST_1"},
+		"status":    "ENABLED",
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	ws, es := resource.Validate(terraform.NewResourceConfig(c))
+	if len(es) != 0 {
+		t.Fatalf("expected a deprecated type to still be allowed, got: %v", es)
+	}
+	if len(ws) != 1 || !strings.Contains(ws[0], "use SCRIPT_BROWSER instead") {
+		t.Fatalf("expected a deprecation warning, got: %v", ws)
+	}
+
+	if ws, es := validateMonitorType("SIMPLE", "type"); len(ws) != 0 || len(es) != 0 {
+		t.Fatalf("expected no warnings or errors for SIMPLE, got: %v, %v", ws, es)
+	}
+}
+
+func TestNRSMonitorValidationStringLength(t *testing.T) {
+	for length, valid := range map[int]bool{
+		maxValidationStringLength:     true,
+		maxValidationStringLength + 1: false,
+	} {
+		c, err := config.NewRawConfig(map[string]interface{}{
+			"name":              "monitor",
+			"type":              "SIMPLE",
+			"frequency":         10,
+			"locations":         []interface{}{"AWS_US_WEST_1"},
+			"status":            "ENABLED",
+			"validation_string": strings.Repeat("a", length),
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		_, es := Provider().ValidateResource("nrs_monitor", terraform.NewResourceConfig(c))
+		if valid && len(es) != 0 {
+			t.Fatalf("expected a validation string of length %d to be valid, got: %v", length, es)
+		}
+		if !valid && len(es) == 0 {
+			t.Fatalf("expected a validation string of length %d to be invalid", length)
+		}
+	}
+}
+
+func TestNRSMonitorNameLength(t *testing.T) {
+	for length, valid := range map[int]bool{
+		0:                        false,
+		len("monitor"):           true,
+		maxMonitorNameLength:     true,
+		maxMonitorNameLength + 1: false,
+	} {
+		c, err := config.NewRawConfig(map[string]interface{}{
+			"name":      strings.Repeat("a", length),
+			"type":      "SIMPLE",
+			"frequency": 10,
+			"locations": []interface{}{"AWS_US_WEST_1"},
+			"status":    "ENABLED",
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		_, es := Provider().ValidateResource("nrs_monitor", terraform.NewResourceConfig(c))
+		if valid && len(es) != 0 {
+			t.Fatalf("expected a name of length %d to be valid, got: %v", length, es)
+		}
+		if !valid && len(es) == 0 {
+			t.Fatalf("expected a name of length %d to be invalid", length)
+		}
+	}
+}
+
+func TestNRSMonitorReadWithoutSLAThreshold(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.Handle("GET", monitorPath(testMonitorID), jsonResponse(http.StatusOK, `{
+		"id": "`+testMonitorID+`",
+		"name": "monitor",
+		"type": "SIMPLE",
+		"frequency": 10,
+		"uri": "https://example.com",
+		"locations": ["AWS_US_WEST_1"],
+		"status": "ENABLED"
+	}`))
+
+	state := readMonitor(t, fake, testMonitorID)
+	diff := planResource(t, NRSMonitorResource(), state, map[string]interface{}{
+		"name":      "monitor",
+		"type":      "SIMPLE",
+		"frequency": 10,
+		"uri":       "https://example.com",
+		"locations": []interface{}{"AWS_US_WEST_1"},
+		"status":    "ENABLED",
+	})
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan, got: %#v", diff.Attributes)
+	}
+}
+
+// requestOptions decodes the options sent in a monitor request body.
+func requestOptions(t *testing.T, request fakeRequest) map[string]interface{} {
+	var body struct {
+		Options map[string]interface{} `json:"options"`
+	}
+	if err := json.Unmarshal([]byte(request.Body), &body); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return body.Options
+}
+
+func TestNRSMonitorCreateValidationString(t *testing.T) {
+	for _, validationString := range []string{"", "OK"} {
+		fake := newFakeSynthetics()
+		fake.handleCreateMonitor(testMonitorID, `{"id": "`+testMonitorID+`", "type": "SIMPLE", "slaThreshold": 7}`)
+
+		raw := map[string]interface{}{
+			"name":      "monitor",
+			"type":      "SIMPLE",
+			"frequency": 10,
+			"uri":       "https://example.com",
+			"locations": []interface{}{"AWS_US_WEST_1"},
+			"status":    "ENABLED",
+		}
+		if validationString != "" {
+			raw["validation_string"] = validationString
+		}
+		resourceData := schema.TestResourceDataRaw(t, NRSMonitorResource().Schema, raw)
+		if err := NRSMonitorCreate(resourceData, fake.Meta(t)); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		options := requestOptions(t, fake.Requests("POST", "/synthetics/api/v3/monitors")[0])
+		got, ok := options["validationString"]
+		if validationString == "" && ok {
+			t.Fatalf("expected no validation string to be sent, got %q", got)
+		}
+		if validationString != "" && got != validationString {
+			t.Fatalf("expected validation string %q to be sent, got %v", validationString, got)
+		}
+	}
+}
+
+func TestNRSMonitorUpdateValidationString(t *testing.T) {
+	cases := []struct {
+		from, to string
+	}{
+		{from: "OK", to: ""},
+		{from: "", to: "OK"},
+	}
+
+	for _, c := range cases {
+		fake := newFakeSynthetics()
+		monitorJSON := `{
+			"id": "` + testMonitorID + `",
+			"name": "monitor",
+			"type": "SIMPLE",
+			"frequency": 10,
+			"uri": "https://example.com",
+			"locations": ["AWS_US_WEST_1"],
+			"status": "ENABLED",
+			"slaThreshold": 7`
+		if c.from != "" {
+			monitorJSON += `, "options": {"validationString": "` + c.from + `"}`
+		}
+		fake.Handle("GET", monitorPath(testMonitorID), jsonResponse(http.StatusOK, monitorJSON+"}"))
+		fake.Handle("PATCH", monitorPath(testMonitorID), jsonResponse(http.StatusNoContent, ""))
+
+		state := readMonitor(t, fake, testMonitorID)
+		raw := map[string]interface{}{
+			"name":          "monitor",
+			"type":          "SIMPLE",
+			"frequency":     10,
+			"uri":           "https://example.com",
+			"locations":     []interface{}{"AWS_US_WEST_1"},
+			"status":        "ENABLED",
+			"sla_threshold": 7,
+		}
+		if c.to != "" {
+			raw["validation_string"] = c.to
+		}
+		diff := planResource(t, NRSMonitorResource(), state, raw)
+		if _, err := NRSMonitorResource().Apply(state, diff, fake.Meta(t)); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		requests := fake.Requests("PATCH", monitorPath(testMonitorID))
+		if len(requests) != 1 {
+			t.Fatalf("expected 1 monitor update, got %d", len(requests))
+		}
+		options := requestOptions(t, requests[0])
+		if got, ok := options["validationString"]; !ok || got != c.to {
+			t.Fatalf("%q -> %q: expected validation string %q to be sent, got %v", c.from, c.to, c.to, options)
+		}
+	}
+}
+
+func TestNRSMonitorReadEmptyAndMissingScripts(t *testing.T) {
+	monitorJSON := `{
+		"id": "` + testMonitorID + `",
+		"name": "script-monitor",
+		"type": "SCRIPT_API",
+		"frequency": 10,
+		"locations": ["AWS_US_WEST_1"],
+		"status": "ENABLED",
+		"slaThreshold": 7
+	}`
+	raw := map[string]interface{}{
+		"name":          "script-monitor",
+		"type":          "SCRIPT_API",
+		"frequency":     10,
+		"locations":     []interface{}{"AWS_US_WEST_1"},
+		"status":        "ENABLED",
+		"sla_threshold": 7,
+	}
+
+	// An empty script and a missing one (a 404 from the API) both
+	// match a configuration without a script.
+	for _, scriptHandler := range []http.HandlerFunc{
+		jsonResponse(http.StatusOK, `{"scriptText": ""}`),
+		jsonResponse(http.StatusNotFound, ""),
+	} {
+		fake := newFakeSynthetics()
+		fake.Handle("GET", monitorPath(testMonitorID), jsonResponse(http.StatusOK, monitorJSON))
+		fake.Handle("GET", monitorPath(testMonitorID)+"/script", scriptHandler)
+
+		state := readMonitor(t, fake, testMonitorID)
+		if script := state.Attributes["script"]; script != "" {
+			t.Fatalf("expected no script in state, got %q", script)
+		}
+		if diff := planResource(t, NRSMonitorResource(), state, raw); !diff.Empty() {
+			t.Fatalf("expected an empty plan, got: %#v", diff.Attributes)
+		}
+	}
+}
+
+func TestNRSMonitorDeleteWaitsForRemoval(t *testing.T) {
+	deletePollInterval = 0
+	defer func() { deletePollInterval = 5 * time.Second }()
+
+	fake := newFakeSynthetics()
+	fake.Handle("DELETE", monitorPath(testMonitorID), jsonResponse(http.StatusNoContent, ""))
+	polls := 0
+	fake.Handle("GET", monitorPath(testMonitorID), func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		if polls == 1 {
+			jsonResponse(http.StatusOK, `{"id": "`+testMonitorID+`"}`)(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	state := &terraform.InstanceState{
+		ID:         testMonitorID,
+		Attributes: map[string]string{"wait_for_delete": "true"},
+	}
+	if _, err := NRSMonitorResource().Apply(state, &terraform.InstanceDiff{Destroy: true}, fake.Meta(t)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if polls != 2 {
+		t.Fatalf("expected to poll until the monitor was gone, polled %d times", polls)
+	}
+}
+
+func TestNRSMonitorReadMonitorURL(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.Handle("GET", monitorPath(testMonitorID), jsonResponse(http.StatusOK, `{
+		"id": "`+testMonitorID+`",
+		"name": "monitor",
+		"type": "SIMPLE",
+		"frequency": 10,
+		"locations": ["AWS_US_WEST_1"],
+		"status": "ENABLED"
+	}`))
+
+	for _, test := range []struct {
+		region    string
+		accountID int
+		expected  string
+	}{
+		{"US", testAccountID, "https://synthetics.newrelic.com/accounts/1234567/monitors/" + testMonitorID},
+		{"EU", testAccountID, "https://synthetics.eu.newrelic.com/accounts/1234567/monitors/" + testMonitorID},
+		{"US", 0, ""},
+	} {
+		providerData := schema.TestResourceDataRaw(t, Provider().(*schema.Provider).Schema, map[string]interface{}{
+			"newrelic_api_key": "test-api-key",
+			"account_id":       test.accountID,
+			"region":           test.region,
+		})
+		meta, err := getClient(providerData)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		meta.(*providerMeta).client = fake.Client(t)
+
+		resourceData := NRSMonitorResource().Data(&terraform.InstanceState{ID: testMonitorID})
+		if err := NRSMonitorRead(resourceData, meta); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if got := resourceData.Get("monitor_url").(string); got != test.expected {
+			t.Fatalf("%s account %d: expected monitor_url %q, got %q", test.region, test.accountID, test.expected, got)
+		}
+	}
+}
+
+func TestNRSMonitorCreateSetsComputedAttributes(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.handleCreateMonitor(testMonitorID, `{
+		"id": "`+testMonitorID+`",
+		"name": "monitor",
+		"type": "SIMPLE",
+		"frequency": 10,
+		"uri": "https://example.com",
+		"locations": ["AWS_US_WEST_1"],
+		"status": "ENABLED",
+		"options": {"verifySSL": false}
+	}`)
+
+	raw := map[string]interface{}{
+		"name":      "monitor",
+		"type":      "SIMPLE",
+		"uri":       "https://example.com",
+		"locations": []interface{}{"AWS_US_WEST_1"},
+		"status":    "ENABLED",
+	}
+	state, err := NRSMonitorResource().Apply(nil, planResource(t, NRSMonitorResource(), nil, raw), fake.Meta(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// No reads happen after Create, so these must come from the
+	// monitor New Relic returned when it was created.
+	for attribute, expected := range map[string]string{
+		"monitor_url":     "https://synthetics.newrelic.com/accounts/1234567/monitors/" + testMonitorID,
+		"options_json":    `{"verifySSL":false}`,
+		"frequency":       "10",
+		"frequency_human": "every 10 minutes",
+		"has_script":      "false",
+	} {
+		if got := state.Attributes[attribute]; got != expected {
+			t.Fatalf("expected %s %s after create, got %q", attribute, expected, got)
+		}
+	}
+	if gets := len(fake.Requests("GET", monitorPath(testMonitorID))); gets != 1 {
+		t.Fatalf("expected only the synthetics client's read after creating, got %d", gets)
+	}
+}
+
+func TestNRSMonitorUnmanagedScript(t *testing.T) {
+	monitorJSON := `{
+		"id": "` + testMonitorID + `",
+		"name": "monitor",
+		"type": "SCRIPT_API",
+		"frequency": 10,
+		"locations": ["AWS_US_WEST_1"],
+		"status": "ENABLED"
+	}`
+	fake := newFakeSynthetics()
+	fake.handleCreateMonitor(testMonitorID, monitorJSON)
+	fake.Handle("PATCH", monitorPath(testMonitorID), jsonResponse(http.StatusNoContent, ""))
+
+	raw := map[string]interface{}{
+		"name":          "monitor",
+		"type":          "SCRIPT_API",
+		"frequency":     10,
+		"locations":     []interface{}{"AWS_US_WEST_1"},
+		"status":        "ENABLED",
+		"script":        "console.log('check');",
+		"manage_script": false,
+	}
+	resourceData := schema.TestResourceDataRaw(t, NRSMonitorResource().Schema, raw)
+	if err := NRSMonitorCreate(resourceData, fake.Meta(t)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := NRSMonitorRead(resourceData, fake.Meta(t)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	raw["script"] = "console.log('changed');"
+	diff := planResource(t, NRSMonitorResource(), resourceData.State(), raw)
+	if _, err := NRSMonitorResource().Apply(resourceData.State(), diff, fake.Meta(t)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if got := len(fake.Requests("PUT", monitorPath(testMonitorID)+"/script")); got != 0 {
+		t.Fatalf("expected no script updates, got %d", got)
+	}
+	if got := len(fake.Requests("GET", monitorPath(testMonitorID)+"/script")); got != 0 {
+		t.Fatalf("expected no script reads, got %d", got)
+	}
+}
+
+func TestNRSMonitorManageScriptDefault(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.Handle("GET", monitorPath(testMonitorID), jsonResponse(http.StatusOK, `{
+		"id": "`+testMonitorID+`",
+		"name": "monitor",
+		"type": "SCRIPT_API",
+		"frequency": 10,
+		"locations": ["AWS_US_WEST_1"],
+		"status": "ENABLED"
+	}`))
+	fake.Handle("GET", monitorPath(testMonitorID)+"/script", jsonResponse(http.StatusOK, `{"scriptText": "`+base64.StdEncoding.EncodeToString([]byte("console.log('check');"))+`"}`))
+
+	// A state from before manage_script existed still has its script
+	// managed, and doesn't plan a change to manage_script.
+	state := readMonitor(t, fake, testMonitorID)
+	if got := len(fake.Requests("GET", monitorPath(testMonitorID)+"/script")); got != 1 {
+		t.Fatalf("expected the script to be read, got %d requests", got)
+	}
+	diff := planResource(t, NRSMonitorResource(), state, map[string]interface{}{
+		"name":      "monitor",
+		"type":      "SCRIPT_API",
+		"frequency": 10,
+		"locations": []interface{}{"AWS_US_WEST_1"},
+		"status":    "ENABLED",
+		"script":    "console.log('check');",
+	})
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan, got: %#v", diff.Attributes)
+	}
+}
+
+func TestNRSMonitorConcurrentReadsShareRequests(t *testing.T) {
+	release := make(chan struct{})
+	fake := newFakeSynthetics()
+	fake.Handle("GET", monitorPath(testMonitorID), func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		jsonResponse(http.StatusOK, `{
+			"id": "`+testMonitorID+`",
+			"name": "monitor",
+			"type": "SCRIPT_API",
+			"frequency": 10,
+			"locations": ["AWS_US_WEST_1"],
+			"status": "ENABLED"
+		}`)(w, r)
+	})
+	fake.Handle("GET", monitorPath("missing-id"), func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusNotFound)
+	})
+	fake.Handle("GET", monitorPath(testMonitorID)+"/script", jsonResponse(http.StatusOK, `{"scriptText": "`+base64.StdEncoding.EncodeToString([]byte("console.log('check');"))+`"}`))
+
+	meta := fake.Meta(t)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			resourceData := NRSMonitorResource().Data(&terraform.InstanceState{ID: testMonitorID})
+			if err := NRSMonitorRead(resourceData, meta); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			exists, err := NRSMonitorExists(NRSMonitorResource().Data(&terraform.InstanceState{ID: "missing-id"}), meta)
+			if err != nil || exists {
+				t.Errorf("expected a missing monitor, got %v, %v", exists, err)
+			}
+		}()
+	}
+
+	// Give every read time to start before any request completes.
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := len(fake.Requests("GET", monitorPath(testMonitorID))); got != 1 {
+		t.Fatalf("expected 1 monitor request, got %d", got)
+	}
+	if got := len(fake.Requests("GET", monitorPath("missing-id"))); got != 1 {
+		t.Fatalf("expected 1 request for the missing monitor, got %d", got)
+	}
+}
+
+func TestNRSMonitorCreatePrivateOnly(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.handleCreateMonitor(testMonitorID, `{
+		"id": "`+testMonitorID+`",
+		"name": "monitor",
+		"type": "SCRIPT_API",
+		"frequency": 10,
+		"locations": [],
+		"status": "ENABLED"
+	}`)
+	fake.Handle("GET", monitorPath(testMonitorID)+"/script", jsonResponse(http.StatusOK, `{"scriptText": "`+base64.StdEncoding.EncodeToString([]byte("console.log('check');"))+`"}`))
+
+	raw := map[string]interface{}{
+		"name":      "monitor",
+		"type":      "SCRIPT_API",
+		"frequency": 10,
+		"status":    "ENABLED",
+		"script":    "console.log('check');",
+		"script_locations": []interface{}{
+			map[string]interface{}{"name": "private", "hmac": "hmac"},
+		},
+	}
+	resourceData := schema.TestResourceDataRaw(t, NRSMonitorResource().Schema, raw)
+	if err := NRSMonitorCreate(resourceData, fake.Meta(t)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var body struct {
+		Locations []string `json:"locations"`
+	}
+	if err := json.Unmarshal([]byte(fake.Requests("POST", "/synthetics/api/v3/monitors")[0].Body), &body); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if body.Locations == nil || len(body.Locations) != 0 {
+		t.Fatalf("expected an empty locations array, got: %s", fake.Requests("POST", "/synthetics/api/v3/monitors")[0].Body)
+	}
+
+	var script struct {
+		ScriptLocations []map[string]string `json:"scriptLocations"`
+	}
+	if err := json.Unmarshal([]byte(fake.Requests("PUT", monitorPath(testMonitorID)+"/script")[0].Body), &script); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(script.ScriptLocations) != 1 || script.ScriptLocations[0]["name"] != "private" {
+		t.Fatalf("expected the script location to be sent, got: %v", script.ScriptLocations)
+	}
+
+	if err := NRSMonitorRead(resourceData, fake.Meta(t)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	diff := planResource(t, NRSMonitorResource(), resourceData.State(), raw)
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan, got: %#v", diff.Attributes)
+	}
+}
+
+func writeHMACFile(t *testing.T, hmac string) string {
+	f, err := ioutil.TempFile("", "hmac")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(hmac + "\n"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return f.Name()
+}
+
+func scriptRequestLocations(t *testing.T, request fakeRequest) []map[string]string {
+	var script struct {
+		ScriptLocations []map[string]string `json:"scriptLocations"`
+	}
+	if err := json.Unmarshal([]byte(request.Body), &script); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return script.ScriptLocations
+}
+
+func TestNRSMonitorScriptLocationHMACFile(t *testing.T) {
+	first := writeHMACFile(t, "first-secret")
+	defer os.Remove(first)
+	second := writeHMACFile(t, "second-secret")
+	defer os.Remove(second)
+
+	fake := newFakeSynthetics()
+	fake.handleCreateMonitor(testMonitorID, `{
+		"id": "`+testMonitorID+`",
+		"name": "monitor",
+		"type": "SCRIPT_API",
+		"frequency": 10,
+		"locations": [],
+		"status": "ENABLED"
+	}`)
+	fake.Handle("GET", monitorPath(testMonitorID)+"/script", jsonResponse(http.StatusOK, `{"scriptText": "`+base64.StdEncoding.EncodeToString([]byte("console.log('check');"))+`"}`))
+
+	raw := map[string]interface{}{
+		"name":      "monitor",
+		"type":      "SCRIPT_API",
+		"frequency": 10,
+		"status":    "ENABLED",
+		"script":    "console.log('check');",
+		"script_locations": []interface{}{
+			map[string]interface{}{"name": "private", "hmac_file": first},
+		},
+	}
+	state, err := NRSMonitorResource().Apply(nil, planResource(t, NRSMonitorResource(), nil, raw), fake.Meta(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	puts := fake.Requests("PUT", monitorPath(testMonitorID)+"/script")
+	if got := scriptRequestLocations(t, puts[0]); len(got) != 1 || got[0]["hmac"] != "first-secret" {
+		t.Fatalf("expected the HMAC from the file to be sent on create, got: %v", got)
+	}
+
+	raw["script_locations"] = []interface{}{
+		map[string]interface{}{"name": "private", "hmac_file": second},
+	}
+	state, err = NRSMonitorResource().Apply(state, planResource(t, NRSMonitorResource(), state, raw), fake.Meta(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	puts = fake.Requests("PUT", monitorPath(testMonitorID)+"/script")
+	if len(puts) != 2 {
+		t.Fatalf("expected the script to be resent on update, got %d PUTs", len(puts))
+	}
+	if got := scriptRequestLocations(t, puts[1]); len(got) != 1 || got[0]["hmac"] != "second-secret" {
+		t.Fatalf("expected the HMAC from the new file to be sent on update, got: %v", got)
+	}
+	if !strings.Contains(puts[1].Body, base64.StdEncoding.EncodeToString([]byte("console.log('check');"))) {
+		t.Fatalf("expected the current script to be resent, got: %s", puts[1].Body)
+	}
+
+	for key, value := range state.Attributes {
+		if strings.Contains(value, "secret") {
+			t.Fatalf("expected no HMAC in state, got %s = %q", key, value)
+		}
+	}
+}
+
+func TestNRSMonitorScriptLocationHMACFileRotation(t *testing.T) {
+	path := writeHMACFile(t, "first-secret")
+	defer os.Remove(path)
+
+	fake := newFakeSynthetics()
+	fake.handleCreateMonitor(testMonitorID, `{
+		"id": "`+testMonitorID+`",
+		"name": "monitor",
+		"type": "SCRIPT_API",
+		"frequency": 10,
+		"locations": [],
+		"status": "ENABLED"
+	}`)
+	fake.Handle("GET", monitorPath(testMonitorID)+"/script", jsonResponse(http.StatusOK, `{"scriptText": "`+base64.StdEncoding.EncodeToString([]byte("console.log('check');"))+`"}`))
+
+	raw := map[string]interface{}{
+		"name":      "monitor",
+		"type":      "SCRIPT_API",
+		"frequency": 10,
+		"status":    "ENABLED",
+		"script":    "console.log('check');",
+		"script_locations": []interface{}{
+			map[string]interface{}{"name": "private", "hmac_file": path},
+		},
+	}
+	state, err := NRSMonitorResource().Apply(nil, planResource(t, NRSMonitorResource(), nil, raw), fake.Meta(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := state.Attributes["script_locations.0.hmac_file_hash"]; got != hmacHash("first-secret") {
+		t.Fatalf("expected a hash of the HMAC in state, got %q", got)
+	}
+
+	// An unchanged file plans no changes.
+	state, err = NRSMonitorResource().Refresh(state, fake.Meta(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff := planResource(t, NRSMonitorResource(), state, raw); !diff.Empty() {
+		t.Fatalf("expected an empty plan, got: %#v", diff.Attributes)
+	}
+
+	// Rotating the HMAC in the same file plans an update that resends
+	// it.
+	if err := ioutil.WriteFile(path, []byte("second-secret\n"), 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	state, err = NRSMonitorResource().Refresh(state, fake.Meta(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	diff := planResource(t, NRSMonitorResource(), state, raw)
+	if diff.Empty() || diff.RequiresNew() {
+		t.Fatalf("expected an in-place update after the HMAC file changed, got: %#v", diff)
+	}
+	state, err = NRSMonitorResource().Apply(state, diff, fake.Meta(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	puts := fake.Requests("PUT", monitorPath(testMonitorID)+"/script")
+	if got := scriptRequestLocations(t, puts[len(puts)-1]); len(got) != 1 || got[0]["hmac"] != "second-secret" {
+		t.Fatalf("expected the rotated HMAC to be sent, got: %v", got)
+	}
+
+	state, err = NRSMonitorResource().Refresh(state, fake.Meta(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff := planResource(t, NRSMonitorResource(), state, raw); !diff.Empty() {
+		t.Fatalf("expected an empty plan after resending the HMAC, got: %#v", diff.Attributes)
+	}
+}
+
+func TestValidateMonitorHMACAndHMACFile(t *testing.T) {
+	resourceData := schema.TestResourceDataRaw(t, NRSMonitorResource().Schema, map[string]interface{}{
+		"name":   "monitor",
+		"type":   "SCRIPT_API",
+		"status": "ENABLED",
+		"script": "console.log('check');",
+		"script_locations": []interface{}{
+			map[string]interface{}{"name": "private", "hmac": "hmac", "hmac_file": "/dev/null"},
+		},
+	})
+	if err := validateMonitor(resourceData, 10); err == nil || !strings.Contains(err.Error(), "both hmac and hmac_file") {
+		t.Fatalf("expected an error about setting both hmac and hmac_file, got: %v", err)
+	}
+}
+
+func TestValidateMonitorDuplicateLocations(t *testing.T) {
+	resourceData := schema.TestResourceDataRaw(t, NRSMonitorResource().Schema, map[string]interface{}{
+		"name":   "monitor",
+		"type":   "SCRIPT_API",
+		"status": "ENABLED",
+		"script": "console.log('check');",
+		"script_locations": []interface{}{
+			map[string]interface{}{"name": "private", "hmac": "hmac"},
+			map[string]interface{}{"name": "private", "hmac": "other"},
+		},
+	})
+	if err := validateMonitor(resourceData, 10); err == nil || !strings.Contains(err.Error(), "script location private is listed more than once") {
+		t.Fatalf("expected an error naming the duplicate script location, got: %v", err)
+	}
+
+	// A duplicated public location is a single element of the set, so
+	// it's sent to New Relic once.
+	resourceData = schema.TestResourceDataRaw(t, NRSMonitorResource().Schema, map[string]interface{}{
+		"name":      "monitor",
+		"type":      "SIMPLE",
+		"frequency": 10,
+		"uri":       "https://example.com",
+		"locations": []interface{}{"AWS_US_WEST_1", "aws_us_west_1"},
+		"status":    "ENABLED",
+	})
+	if err := validateMonitor(resourceData, 10); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if locations := resourceData.Get("locations").(*schema.Set).Len(); locations != 1 {
+		t.Fatalf("expected the duplicate location to collapse into one, got %d", locations)
+	}
+}
+
+func TestValidateMonitorScriptURI(t *testing.T) {
+	raw := map[string]interface{}{
+		"name":      "monitor",
+		"type":      "SCRIPT_API",
+		"frequency": 10,
+		"locations": []interface{}{"AWS_US_WEST_1"},
+		"status":    "ENABLED",
+		"script":    "console.log('check');",
+	}
+	if err := validateMonitor(schema.TestResourceDataRaw(t, NRSMonitorResource().Schema, raw), 10); err != nil {
+		t.Fatalf("expected a SCRIPT_API monitor without a uri to be valid, got: %s", err)
+	}
+
+	raw["uri"] = "https://example.com"
+	err := validateMonitor(schema.TestResourceDataRaw(t, NRSMonitorResource().Schema, raw), 10)
+	if err == nil || !strings.Contains(err.Error(), "uri is not supported for SCRIPT_API monitors") {
+		t.Fatalf("expected an error about uri on a SCRIPT_API monitor, got: %v", err)
+	}
+}
+
+func TestNRSMonitorScriptOnlyUpdateSkipsUpdateMonitor(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.handleCreateMonitor(testMonitorID, `{
+		"id": "`+testMonitorID+`",
+		"name": "monitor",
+		"type": "SCRIPT_API",
+		"frequency": 10,
+		"locations": ["AWS_US_WEST_1"],
+		"status": "ENABLED"
+	}`)
+	fake.Handle("GET", monitorPath(testMonitorID)+"/script", jsonResponse(http.StatusOK, `{"scriptText": "`+base64.StdEncoding.EncodeToString([]byte("console.log('check');"))+`"}`))
+	fake.Handle("PATCH", monitorPath(testMonitorID), jsonResponse(http.StatusNoContent, ""))
+
+	raw := map[string]interface{}{
+		"name":      "monitor",
+		"type":      "SCRIPT_API",
+		"frequency": 10,
+		"locations": []interface{}{"AWS_US_WEST_1"},
+		"status":    "ENABLED",
+		"script":    "console.log('check');",
+	}
+	state, err := NRSMonitorResource().Apply(nil, planResource(t, NRSMonitorResource(), nil, raw), fake.Meta(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	raw["script"] = "console.log('changed');"
+	if _, err := NRSMonitorResource().Apply(state, planResource(t, NRSMonitorResource(), state, raw), fake.Meta(t)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := len(fake.Requests("PUT", monitorPath(testMonitorID)+"/script")); got != 2 {
+		t.Fatalf("expected the script to be updated, got %d script PUTs", got)
+	}
+	if got := len(fake.Requests("PATCH", monitorPath(testMonitorID))); got != 0 {
+		t.Fatalf("expected no UpdateMonitor call, got %d", got)
+	}
+}
+
+func TestNRSMonitorVerifySSLDriftIsCorrected(t *testing.T) {
+	monitorJSON := func(verifySSL bool) string {
+		return fmt.Sprintf(`{
+			"id": "`+testMonitorID+`",
+			"name": "monitor",
+			"type": "SIMPLE",
+			"frequency": 10,
+			"uri": "https://example.com",
+			"locations": ["AWS_US_WEST_1"],
+			"status": "ENABLED",
+			"slaThreshold": 7,
+			"options": {"verifySSL": %t}
+		}`, verifySSL)
+	}
+	raw := map[string]interface{}{
+		"name":          "monitor",
+		"type":          "SIMPLE",
+		"frequency":     10,
+		"uri":           "https://example.com",
+		"locations":     []interface{}{"AWS_US_WEST_1"},
+		"status":        "ENABLED",
+		"sla_threshold": 7,
+		"verify_ssl":    true,
+	}
+
+	fake := newFakeSynthetics()
+	fake.Handle("GET", monitorPath(testMonitorID), jsonResponse(http.StatusOK, monitorJSON(true)))
+	if diff := planResource(t, NRSMonitorResource(), readMonitor(t, fake, testMonitorID), raw); !diff.Empty() {
+		t.Fatalf("expected an empty plan, got: %#v", diff.Attributes)
+	}
+
+	// verify_ssl is turned off outside of Terraform.
+	fake.Handle("GET", monitorPath(testMonitorID), jsonResponse(http.StatusOK, monitorJSON(false)))
+	fake.Handle("PATCH", monitorPath(testMonitorID), jsonResponse(http.StatusNoContent, ""))
+	state := readMonitor(t, fake, testMonitorID)
+	diff := planResource(t, NRSMonitorResource(), state, raw)
+	if attr, ok := diff.Attributes["verify_ssl"]; !ok || attr.Old != "false" || attr.New != "true" {
+		t.Fatalf("expected verify_ssl to be corrected, got: %#v", diff.Attributes)
+	}
+
+	if _, err := NRSMonitorResource().Apply(state, diff, fake.Meta(t)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	requests := fake.Requests("PATCH", monitorPath(testMonitorID))
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 monitor update, got %d", len(requests))
+	}
+	if got := requestOptions(t, requests[0])["verifySSL"]; got != true {
+		t.Fatalf("expected verifySSL true to be sent, got %v", got)
+	}
+}
+
+func TestNRSMonitorFrequencyHuman(t *testing.T) {
+	expected := map[int]string{
+		1:    "every minute",
+		5:    "every 5 minutes",
+		10:   "every 10 minutes",
+		15:   "every 15 minutes",
+		30:   "every 30 minutes",
+		60:   "every hour",
+		360:  "every 6 hours",
+		720:  "every 12 hours",
+		1440: "every day",
+	}
+	if len(expected) != len(monitorFrequencies) {
+		t.Fatalf("expected a name for each of %v", monitorFrequencies)
+	}
+	for _, frequency := range monitorFrequencies {
+		fake := newFakeSynthetics()
+		fake.Handle("GET", monitorPath(testMonitorID), jsonResponse(http.StatusOK, fmt.Sprintf(`{
+			"id": "`+testMonitorID+`",
+			"name": "monitor",
+			"type": "SIMPLE",
+			"frequency": %d,
+			"uri": "https://example.com",
+			"locations": ["AWS_US_WEST_1"],
+			"status": "ENABLED"
+		}`, frequency)))
+		state := readMonitor(t, fake, testMonitorID)
+		if got := state.Attributes["frequency_human"]; got != expected[frequency] {
+			t.Fatalf("expected frequency %d to be %q, got %q", frequency, expected[frequency], got)
+		}
+	}
+}
+
+func TestNRSMonitorSLAThresholdPrecision(t *testing.T) {
+	if got := roundSLAThreshold(7.005); got != roundSLAThreshold(roundSLAThreshold(7.005)) {
+		t.Fatalf("expected rounding to be stable, got %v", got)
+	}
+	if got := roundSLAThreshold(7.456); got != 7.46 {
+		t.Fatalf("expected 7.456 to round to 7.46, got %v", got)
+	}
+
+	fake := newFakeSynthetics()
+	fake.handleCreateMonitor(testMonitorID, `{
+		"id": "`+testMonitorID+`",
+		"name": "monitor",
+		"type": "SIMPLE",
+		"frequency": 10,
+		"uri": "https://example.com",
+		"locations": ["AWS_US_WEST_1"],
+		"status": "ENABLED",
+		"slaThreshold": `+strconv.FormatFloat(roundSLAThreshold(7.005), 'f', -1, 64)+`
+	}`)
+
+	raw := map[string]interface{}{
+		"name":          "monitor",
+		"type":          "SIMPLE",
+		"frequency":     10,
+		"uri":           "https://example.com",
+		"locations":     []interface{}{"AWS_US_WEST_1"},
+		"status":        "ENABLED",
+		"sla_threshold": 7.005,
+	}
+	if _, err := NRSMonitorResource().Apply(nil, planResource(t, NRSMonitorResource(), nil, raw), fake.Meta(t)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	var body struct {
+		SLAThreshold float64 `json:"slaThreshold"`
+	}
+	if err := json.Unmarshal([]byte(fake.Requests("POST", "/synthetics/api/v3/monitors")[0].Body), &body); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if body.SLAThreshold != roundSLAThreshold(7.005) {
+		t.Fatalf("expected the rounded SLA threshold to be sent, got %v", body.SLAThreshold)
+	}
+
+	state := readMonitor(t, fake, testMonitorID)
+	if diff := planResource(t, NRSMonitorResource(), state, raw); !diff.Empty() {
+		t.Fatalf("expected 7.005 to round-trip without a diff, got: %#v", diff.Attributes)
+	}
+}
+
+func TestNRSMonitorImportUnknownType(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.Handle("GET", monitorPath(testMonitorID), jsonResponse(http.StatusOK, `{
+		"id": "`+testMonitorID+`",
+		"name": "monitor",
+		"type": "CERT_CHECK",
+		"frequency": 10,
+		"locations": ["AWS_US_WEST_1"],
+		"status": "ENABLED"
+	}`))
+
+	resourceData := NRSMonitorResource().Data(&terraform.InstanceState{ID: testMonitorID})
+	_, err := NRSMonitorImport(resourceData, fake.Meta(t))
+	if err == nil || !strings.Contains(err.Error(), "type CERT_CHECK, which isn't supported") {
+		t.Fatalf("expected a descriptive error about the monitor type, got: %v", err)
+	}
+
+	fake.Handle("GET", monitorPath(testMonitorID), jsonResponse(http.StatusOK, `{
+		"id": "`+testMonitorID+`",
+		"name": "monitor",
+		"type": "SIMPLE",
+		"frequency": 10,
+		"uri": "https://example.com",
+		"locations": ["AWS_US_WEST_1"],
+		"status": "ENABLED"
+	}`))
+	imported, err := NRSMonitorImport(resourceData, fake.Meta(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(imported) != 1 || imported[0].Id() != testMonitorID {
+		t.Fatalf("expected the monitor to be imported, got: %v", imported)
+	}
+}
+
+func TestNRSMonitorImportScriptBrowserPlansNoChanges(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.Handle("GET", monitorPath(testMonitorID), jsonResponse(http.StatusOK, `{
+		"id": "`+testMonitorID+`",
+		"name": "monitor",
+		"type": "SCRIPT_BROWSER",
+		"frequency": 10,
+		"locations": ["AWS_US_WEST_1", "AWS_US_EAST_1"],
+		"status": "ENABLED",
+		"slaThreshold": 7
+	}`))
+	fake.Handle("GET", monitorPath(testMonitorID)+"/script", jsonResponse(http.StatusOK, `{"scriptText": "`+base64.StdEncoding.EncodeToString([]byte("$browser.get('https://example.com');\n"))+`"}`))
+
+	resourceData := NRSMonitorResource().Data(nil)
+	resourceData.SetId(testMonitorID)
+	imported, err := NRSMonitorImport(resourceData, fake.Meta(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := NRSMonitorRead(imported[0], fake.Meta(t)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	diff := planResource(t, NRSMonitorResource(), imported[0].State(), map[string]interface{}{
+		"name":          "monitor",
+		"type":          "SCRIPT_BROWSER",
+		"frequency":     10,
+		"locations":     []interface{}{"AWS_US_EAST_1", "AWS_US_WEST_1"},
+		"status":        "ENABLED",
+		"sla_threshold": 7,
+		"script":        "$browser.get('https://example.com');\n",
+	})
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan after import, got: %#v", diff.Attributes)
+	}
+}
+
+func TestNRSMonitorImportScriptBrowserWithScriptLocations(t *testing.T) {
+	path := writeHMACFile(t, "private-secret")
+	defer os.Remove(path)
+
+	fake := newFakeSynthetics()
+	fake.Handle("GET", monitorPath(testMonitorID), jsonResponse(http.StatusOK, `{
+		"id": "`+testMonitorID+`",
+		"name": "monitor",
+		"type": "SCRIPT_BROWSER",
+		"frequency": 10,
+		"locations": [],
+		"status": "ENABLED",
+		"slaThreshold": 7
+	}`))
+	fake.Handle("GET", monitorPath(testMonitorID)+"/script", jsonResponse(http.StatusOK, `{"scriptText": "`+base64.StdEncoding.EncodeToString([]byte("$browser.get('https://example.com');\n"))+`"}`))
+	fake.Handle("PUT", monitorPath(testMonitorID)+"/script", jsonResponse(http.StatusNoContent, ""))
+
+	resourceData := NRSMonitorResource().Data(nil)
+	resourceData.SetId(testMonitorID)
+	imported, err := NRSMonitorImport(resourceData, fake.Meta(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := NRSMonitorRead(imported[0], fake.Meta(t)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// New Relic doesn't return script locations or their HMACs, so
+	// the first plan after import sets them, and nothing else.
+	raw := map[string]interface{}{
+		"name":          "monitor",
+		"type":          "SCRIPT_BROWSER",
+		"frequency":     10,
+		"status":        "ENABLED",
+		"sla_threshold": 7,
+		"script":        "$browser.get('https://example.com');\n",
+		"script_locations": []interface{}{
+			map[string]interface{}{"name": "private", "hmac_file": path},
+		},
+	}
+	diff := planResource(t, NRSMonitorResource(), imported[0].State(), raw)
+	if diff.Empty() || diff.RequiresNew() {
+		t.Fatalf("expected an in-place update setting script_locations, got: %#v", diff)
+	}
+	for attribute := range diff.Attributes {
+		if !strings.HasPrefix(attribute, "script_locations.") {
+			t.Fatalf("expected only script_locations to change, got: %#v", diff.Attributes)
+		}
+	}
+
+	state, err := NRSMonitorResource().Apply(imported[0].State(), diff, fake.Meta(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	puts := fake.Requests("PUT", monitorPath(testMonitorID)+"/script")
+	if len(puts) != 1 {
+		t.Fatalf("expected the script to be resent once, got %d PUTs", len(puts))
+	}
+	if got := scriptRequestLocations(t, puts[0]); len(got) != 1 || got[0]["name"] != "private" || got[0]["hmac"] != "private-secret" {
+		t.Fatalf("expected the script location to be sent, got: %v", got)
+	}
+	if patches := fake.Requests("PATCH", monitorPath(testMonitorID)); len(patches) != 0 {
+		t.Fatalf("expected the monitor itself not to be updated, got %d PATCHes", len(patches))
+	}
+
+	state, err = NRSMonitorResource().Refresh(state, fake.Meta(t))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff := planResource(t, NRSMonitorResource(), state, raw); !diff.Empty() {
+		t.Fatalf("expected an empty plan once script_locations is applied, got: %#v", diff.Attributes)
+	}
+}
+
+func TestNRSMonitorStateOmitsAPIKey(t *testing.T) {
+	const apiKey = "NRAK-0123456789ABCDEFGHIJKLMNOPQ"
+
+	fake := newFakeSynthetics()
+	fake.handleCreateMonitor(testMonitorID, `{
+		"id": "`+testMonitorID+`",
+		"name": "monitor",
+		"type": "SCRIPT_API",
+		"frequency": 10,
+		"locations": ["AWS_US_WEST_1"],
+		"status": "ENABLED"
+	}`)
+	fake.Handle("GET", monitorPath(testMonitorID)+"/script", jsonResponse(http.StatusOK, `{"scriptText": "`+base64.StdEncoding.EncodeToString([]byte("console.log('check');"))+`"}`))
+
+	// Configure the provider as Terraform would, sending its requests
+	// to the fake.
+	providerData := schema.TestResourceDataRaw(t, Provider().(*schema.Provider).Schema, map[string]interface{}{
+		"newrelic_api_key": apiKey,
+	})
+	meta, err := getClient(providerData)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	var authenticated int
+	meta.(*providerMeta).httpClient.client.Transport = roundTripFunc(func(request *http.Request) (*http.Response, error) {
+		if request.Header.Get("X-Api-Key") == apiKey {
+			authenticated++
+		}
+		return fake.Do(request)
+	})
+
+	raw := map[string]interface{}{
+		"name":      "monitor",
+		"type":      "SCRIPT_API",
+		"frequency": 10,
+		"locations": []interface{}{"AWS_US_WEST_1"},
+		"status":    "ENABLED",
+		"script":    "console.log('check');",
+	}
+	state, err := NRSMonitorResource().Apply(nil, planResource(t, NRSMonitorResource(), nil, raw), meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	state, err = NRSMonitorResource().Refresh(state, meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if authenticated == 0 {
+		t.Fatal("expected requests to authenticate with the API key")
+	}
+
+	if strings.Contains(state.ID, apiKey) {
+		t.Fatalf("expected the API key not to be in the ID, got %q", state.ID)
+	}
+	for key, value := range state.Attributes {
+		if strings.Contains(value, apiKey) {
+			t.Fatalf("expected the API key not to be in state, got %s = %q", key, value)
+		}
+	}
+	for key, value := range state.Meta {
+		if strings.Contains(fmt.Sprint(value), apiKey) {
+			t.Fatalf("expected the API key not to be in state metadata, got %s = %v", key, value)
+		}
+	}
+}
+
+func TestDefaultOptionsForType(t *testing.T) {
+	for _, test := range []struct {
+		monitorType string
+		expected    map[string]bool
+	}{
+		{synthetics.TypeSimple, map[string]bool{"verify_ssl": false, "bypass_head_request": false, "treat_redirect_as_failure": false}},
+		{synthetics.TypeBrowser, map[string]bool{"verify_ssl": false}},
+		{synthetics.TypeScriptAPI, nil},
+		{synthetics.TypeScriptBrowser, nil},
+	} {
+		if got := defaultOptionsForType(test.monitorType); !reflect.DeepEqual(got, test.expected) {
+			t.Fatalf("expected %s defaults %v, got %v", test.monitorType, test.expected, got)
+		}
+	}
+}
+
+func TestNRSMonitorCreateSendsDefaultOptions(t *testing.T) {
+	for _, monitorType := range monitorTypes {
+		uri := "https://example.com"
+		if strings.HasPrefix(monitorType, "SCRIPT_") {
+			uri = ""
+		}
+
+		fake := newFakeSynthetics()
+		fake.handleCreateMonitor(testMonitorID, `{
+			"id": "`+testMonitorID+`",
+			"name": "monitor",
+			"type": "`+monitorType+`",
+			"frequency": 10,
+			"uri": "`+uri+`",
+			"locations": ["AWS_US_WEST_1"],
+			"status": "ENABLED"
+		}`)
+		fake.Handle("GET", monitorPath(testMonitorID)+"/script", jsonResponse(http.StatusOK, `{"scriptText": "`+base64.StdEncoding.EncodeToString([]byte("console.log('check');"))+`"}`))
+
+		raw := map[string]interface{}{
+			"name":      "monitor",
+			"type":      monitorType,
+			"frequency": 10,
+			"locations": []interface{}{"AWS_US_WEST_1"},
+			"status":    "ENABLED",
+		}
+		if uri == "" {
+			raw["script"] = "console.log('check');"
+		} else {
+			raw["uri"] = uri
+		}
+		resourceData := schema.TestResourceDataRaw(t, NRSMonitorResource().Schema, raw)
+		if err := NRSMonitorCreate(resourceData, fake.Meta(t)); err != nil {
+			t.Fatalf("%s: err: %s", monitorType, err)
+		}
+
+		expected := map[string]interface{}{}
+		for attribute, value := range defaultOptionsForType(monitorType) {
+			expected[attribute] = value
+		}
+		sent := requestOptions(t, fake.Requests("POST", "/synthetics/api/v3/monitors")[0])
+		got := map[string]interface{}{}
+		for option, attribute := range map[string]string{
+			"verifySSL":              "verify_ssl",
+			"bypassHEADRequest":      "bypass_head_request",
+			"treatRedirectAsFailure": "treat_redirect_as_failure",
+		} {
+			if value, ok := sent[option]; ok {
+				got[attribute] = value
+			}
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Fatalf("%s: expected default options %v to be sent, got %v", monitorType, expected, got)
+		}
+
+		if err := NRSMonitorRead(resourceData, fake.Meta(t)); err != nil {
+			t.Fatalf("%s: err: %s", monitorType, err)
+		}
+		if diff := planResource(t, NRSMonitorResource(), resourceData.State(), raw); !diff.Empty() {
+			t.Fatalf("%s: expected an empty plan after create, got: %#v", monitorType, diff.Attributes)
+		}
+	}
+}
+
+func TestNRSMonitorReadHasScript(t *testing.T) {
+	fake := newFakeSynthetics()
+	fake.Handle("GET", monitorPath(testMonitorID), jsonResponse(http.StatusOK, `{
+		"id": "`+testMonitorID+`",
+		"name": "script-monitor",
+		"type": "SCRIPT_API",
+		"frequency": 10,
+		"locations": ["AWS_US_WEST_1"],
+		"status": "ENABLED"
+	}`))
+	fake.Handle("GET", monitorPath(testMonitorID)+"/script", jsonResponse(http.StatusOK, `{"scriptText": "`+base64.StdEncoding.EncodeToString([]byte("console.log('check');"))+`"}`))
+	fake.Handle("GET", monitorPath(testSecondMonitorID), jsonResponse(http.StatusOK, `{
+		"id": "`+testSecondMonitorID+`",
+		"name": "simple-monitor",
+		"type": "SIMPLE",
+		"frequency": 10,
+		"uri": "https://example.com",
+		"locations": ["AWS_US_WEST_1"],
+		"status": "ENABLED"
+	}`))
+
+	if got := readMonitor(t, fake, testMonitorID).Attributes["has_script"]; got != "true" {
+		t.Fatalf("expected has_script to be true for a script monitor, got %q", got)
+	}
+	if got := readMonitor(t, fake, testSecondMonitorID).Attributes["has_script"]; got != "false" {
+		t.Fatalf("expected has_script to be false for a SIMPLE monitor, got %q", got)
+	}
+}
+
+func TestNRSMonitorOptionsBlock(t *testing.T) {
+	// BROWSER monitors only support verifying SSL and a validation
+	// string.
+	for monitorType, options := range map[string]string{
+		"SIMPLE": `{
+			"validationString": "OK",
+			"verifySSL": true,
+			"bypassHEADRequest": false,
+			"treatRedirectAsFailure": true
+		}`,
+		"BROWSER": `{
+			"validationString": "OK",
+			"verifySSL": true
+		}`,
+	} {
+		fake := newFakeSynthetics()
+		fake.handleCreateMonitor(testMonitorID, `{
+			"id": "`+testMonitorID+`",
+			"name": "monitor",
+			"type": "`+monitorType+`",
+			"frequency": 10,
+			"uri": "https://example.com",
+			"locations": ["AWS_US_WEST_1"],
+			"status": "ENABLED",
+			"options": `+options+`
+		}`)
+
+		block := map[string]interface{}{
+			"validation_string": "OK",
+			"verify_ssl":        true,
+		}
+		expected := map[string]interface{}{
+			"validationString": "OK",
+			"verifySSL":        true,
+		}
+		if monitorType == "SIMPLE" {
+			block["treat_redirect_as_failure"] = true
+			expected["treatRedirectAsFailure"] = true
+		}
+		raw := map[string]interface{}{
+			"name":      "monitor",
+			"type":      monitorType,
+			"frequency": 10,
+			"uri":       "https://example.com",
+			"locations": []interface{}{"AWS_US_WEST_1"},
+			"status":    "ENABLED",
+			"options":   []interface{}{block},
+		}
+		resourceData := schema.TestResourceDataRaw(t, NRSMonitorResource().Schema, raw)
+		if err := NRSMonitorCreate(resourceData, fake.Meta(t)); err != nil {
+			t.Fatalf("%s: err: %s", monitorType, err)
+		}
+
+		sent := requestOptions(t, fake.Requests("POST", "/synthetics/api/v3/monitors")[0])
+		for option, value := range expected {
+			if sent[option] != value {
+				t.Fatalf("%s: expected %s to be sent as %v, got %v", monitorType, option, value, sent[option])
+			}
+		}
+
+		if err := NRSMonitorRead(resourceData, fake.Meta(t)); err != nil {
+			t.Fatalf("%s: err: %s", monitorType, err)
+		}
+		if validationString := resourceData.Get("options.0.validation_string").(string); validationString != "OK" {
+			t.Fatalf("%s: expected options.0.validation_string to be OK, got %q", monitorType, validationString)
+		}
+		if _, ok := resourceData.GetOk("validation_string"); ok {
+			t.Fatalf("%s: expected the top-level validation_string not to be set", monitorType)
+		}
+		if diff := planResource(t, NRSMonitorResource(), resourceData.State(), raw); !diff.Empty() {
+			t.Fatalf("%s: expected an empty plan after read, got: %#v", monitorType, diff.Attributes)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	synthetics "github.com/dollarshaveclub/new-relic-synthetics-go"
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// testMonitorID is the ID of the monitor most tests create or read,
+// and testSecondMonitorID is that of a second monitor where a test
+// needs two.
+const (
+	testMonitorID       = "2ff7fea8-2a1c-4d39-9ea7-2b1c6f5a4e01"
+	testSecondMonitorID = "3a0c1f6e-5b2d-4e8f-9c7a-1d2e3f4a5b6c"
+)
+
+// testAccountID is the New Relic account ID of the fake's meta.
+const testAccountID = 1234567
+
+// fakeRequest is a request received by fakeSynthetics.
+type fakeRequest struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+// fakeSynthetics is an in-memory stand-in for the New Relic
+// Synthetics API. Requests are routed by method and path, and
+// unrouted requests receive a 404.
+type fakeSynthetics struct {
+	mu       sync.Mutex
+	handlers map[string]http.HandlerFunc
+	requests []fakeRequest
+}
+
+func newFakeSynthetics() *fakeSynthetics {
+	return &fakeSynthetics{handlers: make(map[string]http.HandlerFunc)}
+}
+
+// Handle routes requests with the given method and path to a handler.
+func (f *fakeSynthetics) Handle(method, path string, handler http.HandlerFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handlers[method+" "+path] = handler
+}
+
+// Do implements synthetics.HTTPClient.
+func (f *fakeSynthetics) Do(request *http.Request) (*http.Response, error) {
+	var body []byte
+	if request.Body != nil {
+		body, _ = ioutil.ReadAll(request.Body)
+	}
+
+	f.mu.Lock()
+	f.requests = append(f.requests, fakeRequest{
+		Method: request.Method,
+		Path:   request.URL.Path,
+		Body:   string(body),
+	})
+	handler, ok := f.handlers[request.Method+" "+request.URL.Path]
+	f.mu.Unlock()
+
+	recorder := httptest.NewRecorder()
+	if ok {
+		handler(recorder, request)
+	} else {
+		recorder.WriteHeader(http.StatusNotFound)
+	}
+
+	return recorder.Result(), nil
+}
+
+// Requests returns the requests received with the given method and
+// path.
+func (f *fakeSynthetics) Requests(method, path string) []fakeRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var requests []fakeRequest
+	for _, request := range f.requests {
+		if request.Method == method && request.Path == path {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+// Client returns a synthetics client backed by the fake.
+func (f *fakeSynthetics) Client(t *testing.T) *synthetics.Client {
+	client, err := synthetics.NewClient(func(s *synthetics.Client) {
+		s.APIKey = "test-api-key"
+		s.HTTPClient = f
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return client
+}
+
+// Meta returns provider meta backed by the fake, with the default
+// default_frequency and region, and testAccountID as the account ID.
+func (f *fakeSynthetics) Meta(t *testing.T) *providerMeta {
+	return &providerMeta{
+		client:           f.Client(t),
+		defaultFrequency: 10,
+		accountID:        testAccountID,
+		uiHost:           uiHosts["US"],
+	}
+}
+
+// jsonResponse returns a handler responding with a status code and
+// JSON body.
+func jsonResponse(status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}
+}
+
+// monitorPath returns the API path of a monitor.
+func monitorPath(id string) string {
+	return "/synthetics/api/v3/monitors/" + id
+}
+
+// handleCreateMonitor routes monitor creation to the fake. The
+// created monitor has the given ID and is returned as monitorJSON by
+// GetMonitor. Script updates succeed.
+func (f *fakeSynthetics) handleCreateMonitor(id, monitorJSON string) {
+	f.Handle("POST", "/synthetics/api/v3/monitors", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "https://synthetics.newrelic.com"+monitorPath(id))
+		w.WriteHeader(http.StatusCreated)
+	})
+	f.Handle("GET", monitorPath(id), jsonResponse(http.StatusOK, monitorJSON))
+	f.Handle("PUT", monitorPath(id)+"/script", jsonResponse(http.StatusNoContent, ""))
+}
+
+// planResource diffs a resource's state against a raw configuration.
+func planResource(t *testing.T, resource *schema.Resource, state *terraform.InstanceState, raw map[string]interface{}) *terraform.InstanceDiff {
+	c, err := config.NewRawConfig(raw)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	diff, err := resource.Diff(state, terraform.NewResourceConfig(c))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return diff
+}